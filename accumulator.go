@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Accumulator collects the entries of a day as they arrive, for example from a terminal.
+type Accumulator struct {
+	policy  Policy
+	entries []Entry
+}
+
+// NewAccumulator returns an empty accumulator computing with policy.
+func NewAccumulator(policy Policy) *Accumulator {
+	return &Accumulator{policy: policy}
+}
+
+// Add appends an entry. Entries must arrive in order of time.
+func (a *Accumulator) Add(entry Entry) error {
+	if len(a.entries) > 0 && entry.Time.Before(a.entries[len(a.entries)-1].Time) {
+		return fmt.Errorf("%w: %s entry at %s", ErrUnsortedEntries, entry.Type, entry.Time.Format("15:04"))
+	}
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of all entries added so far.
+func (a *Accumulator) Entries() []Entry {
+	return append([]Entry{}, a.entries...)
+}
+
+// BreakStatus returns the break status based on the work accumulated until now.
+//
+// Without entries, the status is compliant without owed break. Invalid sequences of entries return an error.
+func (a *Accumulator) BreakStatus(now time.Time) (BreakStatus, error) {
+	if len(a.entries) == 0 {
+		return BreakStatus{Compliant: true}, nil
+	}
+	return LiveBreakStatus(a.entries, now, a.policy)
+}
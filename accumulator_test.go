@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccumulatorBreakStatus(t *testing.T) {
+	acc := NewAccumulator(DefaultPolicy)
+	status, err := acc.BreakStatus(tim(8, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Compliant: true}, status)
+
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeCome, Time: tim(8, 0)}))
+	status, err = acc.BreakStatus(tim(13, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Deadline: tim(14, 0), Compliant: true}, status)
+	status, err = acc.BreakStatus(tim(14, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Owed: dur(0, 30), Deadline: tim(14, 0)}, status)
+
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeLeave, Time: tim(14, 10)}))
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeCome, Time: tim(14, 40)}))
+	status, err = acc.BreakStatus(tim(15, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Deadline: tim(17, 30), Compliant: true}, status)
+
+	assert.ErrorIs(t, acc.Add(Entry{Type: EntryTypeLeave, Time: tim(14, 0)}), ErrUnsortedEntries)
+	assert.Len(t, acc.Entries(), 3)
+}
+
+func TestAccumulatorBreakStatusInvalidSequence(t *testing.T) {
+	acc := NewAccumulator(DefaultPolicy)
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeLeave, Time: tim(8, 0)}))
+	_, err := acc.BreakStatus(tim(9, 0))
+	assert.Error(t, err)
+
+	acc = NewAccumulator(DefaultPolicy)
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeCome, Time: tim(8, 0)}))
+	assert.NoError(t, acc.Add(Entry{Type: EntryTypeCome, Time: tim(9, 0)}))
+	_, err = acc.BreakStatus(tim(10, 0))
+	assert.Error(t, err)
+}
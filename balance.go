@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayResult contains the accounted work and break time of a single day.
+type DayResult struct {
+	Date      time.Time
+	WorkTime  time.Duration
+	BreakTime time.Duration
+}
+
+// TargetFunc returns the target work time for a given day.
+type TargetFunc func(date time.Time) time.Duration
+
+// BalanceOptions controls how ComputeBalance treats special days.
+type BalanceOptions struct {
+	// ZeroTargetCountsAsOvertime defines how work on days without target time (like weekends) is handled.
+	// If true, the full work time of such days is added to the balance as overtime.
+	// If false, these days are ignored and their work time is expected to be banked separately.
+	ZeroTargetCountsAsOvertime bool
+}
+
+// ComputeBalance returns the flexi-time balance of all given days compared to their target time.
+func ComputeBalance(days []DayResult, target TargetFunc, opts BalanceOptions) time.Duration {
+	var balance time.Duration
+	for _, day := range days {
+		balance += dayBalance(day, target, opts)
+	}
+	return balance
+}
+
+func dayBalance(day DayResult, target TargetFunc, opts BalanceOptions) time.Duration {
+	targetTime := target(day.Date)
+	if targetTime == 0 && !opts.ZeroTargetCountsAsOvertime {
+		return 0
+	}
+	return day.WorkTime - targetTime
+}
+
+// BalanceSeries returns the cumulative balance after each day, for example to chart overtime over time.
+func BalanceSeries(days []DayResult, target TargetFunc, opts BalanceOptions) []time.Duration {
+	series := make([]time.Duration, len(days))
+	var balance time.Duration
+	for i, day := range days {
+		balance += dayBalance(day, target, opts)
+		series[i] = balance
+	}
+	return series
+}
+
+// RollingAverage returns the average work time of each day and the preceding days within window.
+//
+// The first days are averaged over the shorter available range.
+func RollingAverage(days []DayResult, window int) []time.Duration {
+	window = max(1, window)
+	averages := make([]time.Duration, len(days))
+	var sum time.Duration
+	for i, day := range days {
+		sum += day.WorkTime
+		if i >= window {
+			sum -= days[i-window].WorkTime
+		}
+		averages[i] = sum / time.Duration(min(i+1, window))
+	}
+	return averages
+}
+
+// DaysToClearDeficit returns the number of days working dailyMax instead of dailyTarget needed to clear a deficit.
+func DaysToClearDeficit(deficit, dailyTarget, dailyMax time.Duration) (int, error) {
+	capacity := dailyMax - dailyTarget
+	if capacity <= 0 {
+		return 0, fmt.Errorf("daily max of %s leaves no overtime above the target of %s", formatDurationMinutes(dailyMax), formatDurationMinutes(dailyTarget))
+	}
+	if deficit <= 0 {
+		return 0, nil
+	}
+	return int((deficit + capacity - 1) / capacity), nil
+}
+
+// SplitOvertime returns the part of overtime that is banked as time off in lieu and the part that is paid out.
+//
+// Overtime is banked until the bank reaches bankCap, the rest is paid out.
+func SplitOvertime(overtime, bankCap, alreadyBanked time.Duration) (toBank, toPay time.Duration) {
+	overtime = max(0, overtime)
+	toBank = min(overtime, max(0, bankCap-alreadyBanked))
+	return toBank, overtime - toBank
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBalanceZeroTarget(t *testing.T) {
+	weekdayTarget := func(date time.Time) time.Duration {
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			return 0
+		}
+		return dur(8, 0)
+	}
+
+	days := []DayResult{
+		{Date: date(2019, time.November, 1), WorkTime: dur(8, 30)},
+		{Date: date(2019, time.November, 2), WorkTime: dur(4, 0)},
+	}
+
+	assert.Equal(t, dur(0, 30), ComputeBalance(days, weekdayTarget, BalanceOptions{}))
+	assert.Equal(t, dur(4, 30), ComputeBalance(days, weekdayTarget, BalanceOptions{ZeroTargetCountsAsOvertime: true}))
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBalanceSeries(t *testing.T) {
+	target := func(date time.Time) time.Duration { return dur(8, 0) }
+	days := []DayResult{
+		{Date: date(2019, time.November, 4), WorkTime: dur(8, 30)},
+		{Date: date(2019, time.November, 5), WorkTime: dur(7, 0)},
+		{Date: date(2019, time.November, 6), WorkTime: dur(9, 15)},
+	}
+
+	series := BalanceSeries(days, target, BalanceOptions{})
+	assert.Equal(t, []time.Duration{dur(0, 30), -dur(0, 30), dur(0, 45)}, series)
+	assert.Equal(t, ComputeBalance(days, target, BalanceOptions{}), series[len(series)-1])
+}
+
+func TestRollingAverage(t *testing.T) {
+	workTimes := []time.Duration{dur(7, 0), dur(9, 0), dur(8, 0), dur(8, 0), dur(8, 0), dur(0, 0), dur(0, 0), dur(7, 0), dur(9, 0), dur(10, 0)}
+	days := make([]DayResult, len(workTimes))
+	for i, workTime := range workTimes {
+		days[i] = DayResult{Date: date(2019, time.November, 1+i), WorkTime: workTime}
+	}
+
+	averages := RollingAverage(days, 7)
+	assert.Len(t, averages, 10)
+	assert.Equal(t, dur(7, 0), averages[0])
+	assert.Equal(t, dur(8, 0), averages[1])
+	assert.Equal(t, dur(8, 0), averages[4])
+	assert.Equal(t, dur(40, 0)/7, averages[6])
+	assert.Equal(t, dur(40, 0)/7, averages[7])
+	assert.Equal(t, dur(42, 0)/7, averages[9])
+}
+
+func TestDaysToClearDeficit(t *testing.T) {
+	days, err := DaysToClearDeficit(dur(5, 0), dur(8, 0), dur(10, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, days)
+
+	days, err = DaysToClearDeficit(dur(4, 0), dur(8, 0), dur(10, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, days)
+
+	days, err = DaysToClearDeficit(0, dur(8, 0), dur(10, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, days)
+
+	_, err = DaysToClearDeficit(dur(5, 0), dur(8, 0), dur(8, 0))
+	assert.Error(t, err)
+}
+
+func TestSplitOvertime(t *testing.T) {
+	toBank, toPay := SplitOvertime(dur(2, 0), dur(20, 0), dur(19, 0))
+	assert.Equal(t, dur(1, 0), toBank)
+	assert.Equal(t, dur(1, 0), toPay)
+
+	toBank, toPay = SplitOvertime(dur(2, 0), dur(20, 0), dur(10, 0))
+	assert.Equal(t, dur(2, 0), toBank)
+	assert.Zero(t, toPay)
+
+	toBank, toPay = SplitOvertime(dur(2, 0), dur(20, 0), dur(21, 0))
+	assert.Zero(t, toBank)
+	assert.Equal(t, dur(2, 0), toPay)
+
+	toBank, toPay = SplitOvertime(-dur(1, 0), dur(20, 0), 0)
+	assert.Zero(t, toBank)
+	assert.Zero(t, toPay)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// DayTypeWork denotes a regular day accounted by its entries.
+	DayTypeWork DayType = "work"
+	// DayTypeHoliday denotes a public holiday.
+	DayTypeHoliday DayType = "holiday"
+	// DayTypeVacation denotes a day of vacation.
+	DayTypeVacation DayType = "vacation"
+	// DayTypeSick denotes a day of sick leave.
+	DayTypeSick DayType = "sick"
+)
+
+// DayType denotes how a day in the calendar is accounted.
+type DayType string
+
+// DayInput contains the type and entries of a single calendar day.
+type DayInput struct {
+	Type    DayType
+	Entries []Entry
+}
+
+// CalendarResult contains the accounted times of all calendar days and the resulting balance.
+type CalendarResult struct {
+	// Days are sorted by date.
+	Days []DayResult
+	// WorkTime is the accounted work time of all days including the target time credited for days off.
+	WorkTime  time.Duration
+	BreakTime time.Duration
+	Balance   time.Duration
+}
+
+// ComputeCalendar returns the accounted times and balance of all days.
+//
+// Work days are accounted by their entries, while holidays, vacation and sick days are credited with their target time.
+func ComputeCalendar(days map[time.Time]DayInput, target TargetFunc, policy Policy) (CalendarResult, error) {
+	var calendar CalendarResult
+	now := time.Now()
+	for date, input := range days {
+		var day DayResult
+		switch input.Type {
+		case DayTypeWork:
+			day = DayResult{Date: date}
+			if len(input.Entries) > 0 {
+				var err error
+				day, err = computeDayResult(date, input.Entries, now, policy)
+				if err != nil {
+					return CalendarResult{}, err
+				}
+			}
+		case DayTypeHoliday, DayTypeVacation, DayTypeSick:
+			day = DayResult{Date: date, WorkTime: target(date)}
+		default:
+			return CalendarResult{}, fmt.Errorf("unknown type %q of %s", input.Type, date.Format("2006-01-02"))
+		}
+
+		calendar.Days = append(calendar.Days, day)
+		calendar.WorkTime += day.WorkTime
+		calendar.BreakTime += day.BreakTime
+	}
+
+	slices.SortFunc(calendar.Days, func(a, b DayResult) int {
+		return a.Date.Compare(b.Date)
+	})
+	calendar.Balance = ComputeBalance(calendar.Days, target, BalanceOptions{})
+	return calendar, nil
+}
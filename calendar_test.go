@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeCalendar(t *testing.T) {
+	day := func(date time.Time, leave time.Duration) DayInput {
+		return DayInput{Type: DayTypeWork, Entries: []Entry{
+			{Type: EntryTypeCome, Time: date.Add(dur(8, 0))},
+			{Type: EntryTypeLeave, Time: date.Add(dur(12, 0))},
+			{Type: EntryTypeCome, Time: date.Add(dur(12, 30))},
+			{Type: EntryTypeLeave, Time: date.Add(leave)},
+		}}
+	}
+	target := func(date time.Time) time.Duration {
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			return 0
+		}
+		return dur(8, 0)
+	}
+
+	days := map[time.Time]DayInput{
+		date(2019, time.November, 4): day(date(2019, time.November, 4), dur(16, 30)),
+		date(2019, time.November, 5): day(date(2019, time.November, 5), dur(17, 30)),
+		date(2019, time.November, 6): {Type: DayTypeVacation},
+		date(2019, time.November, 7): day(date(2019, time.November, 7), dur(15, 30)),
+		date(2019, time.November, 8): {Type: DayTypeSick},
+		date(2019, time.November, 9): {Type: DayTypeWork},
+	}
+
+	calendar, err := ComputeCalendar(days, target, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Len(t, calendar.Days, 6)
+	assert.Equal(t, DayResult{Date: date(2019, time.November, 6), WorkTime: dur(8, 0)}, calendar.Days[2])
+	assert.Equal(t, dur(40, 0), calendar.WorkTime)
+	assert.Equal(t, dur(1, 30), calendar.BreakTime)
+	assert.Equal(t, dur(0, 0), calendar.Balance)
+
+	days[date(2019, time.November, 11)] = DayInput{Type: DayTypeWork}
+	calendar, err = ComputeCalendar(days, target, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, -dur(8, 0), calendar.Balance)
+
+	days[date(2019, time.November, 12)] = DayInput{Type: "unknown"}
+	_, err = ComputeCalendar(days, target, DefaultPolicy)
+	assert.Error(t, err)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// IsCompliant returns whether a day complies with all policy rules and the reasons for any violations.
+func IsCompliant(result WorkTimeResult, policy Policy) (bool, []string) {
+	var reasons []string
+
+	if ok, missing := CheckBreakCompliance(result, policy); !ok {
+		breakTime := countedBreak(result, policy)
+		reasons = append(reasons, fmt.Sprintf("break of %s is shorter than the mandated %s", formatDurationMinutes(breakTime), formatDurationMinutes(breakTime+missing)))
+	}
+	if result.WorkTime > policy.MaxWorkTime {
+		reasons = append(reasons, fmt.Sprintf("work time of %s exceeds the maximum of %s", formatDurationMinutes(result.WorkTime), formatDurationMinutes(policy.MaxWorkTime)))
+	}
+	if !policy.InBusinessHours(result.Start) || !policy.InBusinessHours(result.End) {
+		reasons = append(reasons, fmt.Sprintf("working from %s to %s is out of business hours", result.Start.Format("15:04"), result.End.Format("15:04")))
+	}
+
+	return len(reasons) == 0, reasons
+}
+
+// CheckBreakCompliance returns whether the taken break satisfies the mandated break and the missing break time otherwise.
+//
+// The mandated break is based on the work time of the result, which includes trips unless the policy counts them as break.
+// If the policy requires a contiguous break, only the longest single break is considered.
+func CheckBreakCompliance(result WorkTimeResult, policy Policy) (bool, time.Duration) {
+	requiredBreak := policy.RequiredBreakFor(result.WorkTime, result.BreakTime)
+	breakTime := countedBreak(result, policy)
+	if breakTime >= requiredBreak {
+		return true, 0
+	}
+	return false, requiredBreak - breakTime
+}
+
+func countedBreak(result WorkTimeResult, policy Policy) time.Duration {
+	if policy.RequireContiguousBreak {
+		return result.LongestBreak
+	}
+	return result.BreakTime
+}
+
+// ComplianceScore returns a score from 0 to 100 rating how well a day follows the policy and meets the target.
+//
+// The score is composed of:
+//   - 40 points for the mandated break, reduced proportionally to the missing break
+//   - 30 points for a work time within the maximum work time
+//   - 30 points for the target, reduced proportionally to the shortfall and halved for more than an hour of overtime
+func ComplianceScore(result WorkTimeResult, target time.Duration, policy Policy) int {
+	var score float64
+
+	if ok, missing := CheckBreakCompliance(result, policy); ok {
+		score += 40
+	} else {
+		required := countedBreak(result, policy) + missing
+		score += 40 * float64(required-missing) / float64(required)
+	}
+
+	if result.WorkTime <= policy.MaxWorkTime {
+		score += 30
+	}
+
+	if target <= 0 || result.AccountedWorkTime >= target {
+		if result.AccountedWorkTime-target > time.Hour {
+			score += 15
+		} else {
+			score += 30
+		}
+	} else {
+		score += 30 * float64(result.AccountedWorkTime) / float64(target)
+	}
+
+	return int(score)
+}
+
+// CheckContinuousWork returns ErrContinuousWorkExceeded if a single working segment from come to leave is longer than
+// the maximum continuous work time of the policy. Trips do not interrupt a segment. Open days end at now.
+func CheckContinuousWork(entries []Entry, now time.Time, policy Policy) error {
+	if _, err := computeDayTimes(entries, now); err != nil {
+		return err
+	}
+	if policy.MaxContinuousWork <= 0 {
+		return nil
+	}
+
+	for _, work := range workIntervals(entries, now) {
+		if work.Duration() > policy.MaxContinuousWork {
+			return fmt.Errorf("%w: worked %s from %s to %s", ErrContinuousWorkExceeded, formatDurationMinutes(work.Duration()), work.Start.Format("15:04"), work.End.Format("15:04"))
+		}
+	}
+	return nil
+}
+
+// MonthlyBreakViolations returns the sorted dates of all days that do not comply with the mandated break.
+func MonthlyBreakViolations(days map[time.Time][]Entry, policy Policy) ([]time.Time, error) {
+	series, err := BreakComplianceSeries(days, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []time.Time
+	for date, ok := range series {
+		if !ok {
+			violations = append(violations, date)
+		}
+	}
+	slices.SortFunc(violations, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	return violations, nil
+}
+
+// BreakComplianceSeries returns for each day whether it complies with the mandated break.
+//
+// Days without entries are compliant, as no break is required without work.
+func BreakComplianceSeries(days map[time.Time][]Entry, policy Policy) (map[time.Time]bool, error) {
+	series := make(map[time.Time]bool, len(days))
+	for date, entries := range days {
+		if len(entries) == 0 {
+			series[date] = true
+			continue
+		}
+		result, err := ComputeResult(entries, time.Now(), policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s: %s", date.Format("2006-01-02"), err.Error())
+		}
+		series[date], _ = CheckBreakCompliance(result, policy)
+	}
+	return series, nil
+}
+
+// ValidateBreakWindows returns an error if less than minInWindow of the taken breaks lies within the allowed windows.
+func ValidateBreakWindows(entries []Entry, allowed []Interval, minInWindow time.Duration) error {
+	var breakInWindow time.Duration
+	for _, b := range breakIntervals(entries) {
+		for _, window := range allowed {
+			breakInWindow += b.Overlap(window)
+		}
+	}
+
+	if breakInWindow < minInWindow {
+		return fmt.Errorf("only %s of break taken in allowed windows, but %s are required", formatDurationMinutes(breakInWindow), formatDurationMinutes(minInWindow))
+	}
+	return nil
+}
+
+// BreakAdequacyWithPlan returns whether the day will comply with the mandated break after the planned work and break,
+// and the break that is still missing at the end of the plan.
+func BreakAdequacyWithPlan(workSoFar, breakTaken, plannedBreak, plannedWork time.Duration, policy Policy) (bool, time.Duration) {
+	workTime := workSoFar + plannedWork
+	breakTime := breakTaken + plannedBreak
+	missing := max(0, policy.RequiredBreakFor(workTime, breakTime)-breakTime)
+	return missing == 0, missing
+}
+
+// BreakPlacementWindow returns when a break of breakLen may start on a day starting at start, so that it is completed before workThreshold.
+//
+// Breaks can not start before the earliest countable break of the policy. A latest start before the earliest start
+// denotes that no valid placement exists.
+func BreakPlacementWindow(start time.Time, workThreshold, breakLen time.Duration, policy Policy) (earliest, latest time.Time) {
+	return start.Add(policy.EarliestCountableBreak), start.Add(workThreshold).Add(-breakLen)
+}
+
+// Sample is a value at a point in time.
+type Sample struct {
+	Time  time.Time
+	Value time.Duration
+}
+
+// BreakDeficitCurve returns the mandated break that is still missing given the work and break so far, sampled every
+// step from the first entry until the end of the day. Open days end at now.
+//
+// The deficit rises at the thresholds of the break rules and falls while a break is taken.
+func BreakDeficitCurve(entries []Entry, step time.Duration, now time.Time, policy Policy) ([]Sample, error) {
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return nil, err
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
+	work := workIntervals(entries, times.End)
+	breaks := breakIntervals(entries)
+	var samples []Sample
+	for t := times.Start; ; t = t.Add(step) {
+		if t.After(times.End) {
+			t = times.End
+		}
+
+		elapsed := Interval{Start: times.Start, End: t}
+		var workTime, breakTime time.Duration
+		for _, w := range work {
+			workTime += w.Overlap(elapsed)
+		}
+		for _, b := range breaks {
+			breakTime += b.Overlap(elapsed)
+		}
+		samples = append(samples, Sample{Time: t, Value: max(0, policy.RequiredBreakFor(workTime, breakTime)-breakTime)})
+
+		if !t.Before(times.End) {
+			return samples, nil
+		}
+	}
+}
+
+// BreakTimeliness returns how late (positive) or early (negative) the first adequate break started compared to
+// the latest start allowed by the first break rule of the policy.
+//
+// A break is adequate if it lasts at least as long as required by the first break rule.
+func BreakTimeliness(entries []Entry, policy Policy) (time.Duration, error) {
+	times, err := computeDayTimes(entries, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	schedule := policy.BreakSchedule()
+	if len(schedule) == 0 {
+		return 0, nil
+	}
+	rule := schedule[0]
+
+	deadline, ok := workTimeReachedAt(entries, times.End, rule.WorkTime)
+	if !ok {
+		// the threshold was never reached, so no break was required
+		return 0, nil
+	}
+
+	for _, b := range breakIntervals(entries) {
+		if b.Duration() >= rule.BreakTime {
+			return b.Start.Sub(deadline), nil
+		}
+	}
+	return 0, fmt.Errorf("no break of at least %s taken", formatDurationMinutes(rule.BreakTime))
+}
+
+// workTimeReachedAt returns the time when the work time of entries reached workTime.
+func workTimeReachedAt(entries []Entry, now time.Time, workTime time.Duration) (time.Time, bool) {
+	for _, work := range workIntervals(entries, now) {
+		if work.Duration() >= workTime {
+			return work.Start.Add(workTime), true
+		}
+		workTime -= work.Duration()
+	}
+	return time.Time{}, false
+}
+
+// BreakToMakeCompliant returns the minimal break that needs to be added to a day to comply with the mandated break,
+// and the accounted work time that would then be missing to target.
+//
+// As the presence time is fixed, the added break reduces the work time by the same amount. This may lower the
+// mandated break, so the result can be less than the difference between mandated and taken break. The shortfall
+// reports the tradeoff of the correction and is zero if the target is still met.
+func BreakToMakeCompliant(result WorkTimeResult, target time.Duration, policy Policy) (toAdd, shortfall time.Duration) {
+	toAdd = max(0, policy.RequiredBreakFor(result.WorkTime, result.BreakTime)-result.BreakTime)
+	if !policy.BreakBasedOnPresence {
+		for _, rule := range policy.BreakRules {
+			if rule.WorkTime < result.WorkTime {
+				// reduce the work time down to the threshold to avoid the rule
+				toAdd = min(toAdd, max(result.WorkTime-rule.WorkTime, policy.RequiredBreak(rule.WorkTime)-result.BreakTime))
+			}
+		}
+	}
+
+	accountedWorkTime, _ := policy.AccountedWorkTime(result.WorkTime-toAdd, result.BreakTime+toAdd)
+	return toAdd, max(0, target-accountedWorkTime)
+}
+
+// BreakStatus describes the current break situation of a day.
+type BreakStatus struct {
+	// Owed is the mandated break that has not been taken yet.
+	Owed time.Duration
+	// Deadline is the time when the next break rule applies, which may be in the past if break is owed. It is zero if no further rule applies.
+	Deadline  time.Time
+	Compliant bool
+}
+
+// LiveBreakStatus returns the break status of an open day at now.
+func LiveBreakStatus(entries []Entry, now time.Time, policy Policy) (BreakStatus, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return BreakStatus{}, err
+	}
+	return breakStatus(result.WorkTime, countedBreak(result, policy), now, policy, func(workTime time.Duration) (time.Time, bool) {
+		return workTimeReachedAt(entries, now, workTime)
+	}), nil
+}
+
+func breakStatus(workTime, breakTime time.Duration, now time.Time, policy Policy, reachedAt func(workTime time.Duration) (time.Time, bool)) BreakStatus {
+	status := BreakStatus{Compliant: true}
+	if requiredBreak := policy.RequiredBreakFor(workTime, breakTime); breakTime < requiredBreak {
+		status.Owed = requiredBreak - breakTime
+		status.Compliant = false
+	}
+
+	for _, rule := range policy.BreakSchedule() {
+		if rule.BreakTime > breakTime {
+			if t, ok := reachedAt(rule.WorkTime); ok {
+				status.Deadline = t
+			} else {
+				status.Deadline = now.Add(rule.WorkTime - workTime)
+			}
+			break
+		}
+	}
+	return status
+}
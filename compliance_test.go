@@ -0,0 +1,329 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCompliant(t *testing.T) {
+	ok, reasons := IsCompliant(WorkTimeResult{Start: tim(8, 0), End: tim(16, 30), WorkTime: dur(8, 0), BreakTime: dur(0, 30)}, DefaultPolicy)
+	assert.True(t, ok)
+	assert.Empty(t, reasons)
+
+	ok, reasons = IsCompliant(WorkTimeResult{Start: tim(6, 0), End: tim(16, 15), WorkTime: dur(10, 0), BreakTime: dur(0, 15)}, DefaultPolicy)
+	assert.False(t, ok)
+	assert.Equal(t, []string{
+		"break of 00:15 is shorter than the mandated 00:45",
+		"working from 06:00 to 16:15 is out of business hours",
+	}, reasons)
+}
+
+func TestValidateBreakWindows(t *testing.T) {
+	allowed := []Interval{{Start: tim(11, 30), End: tim(14, 0)}}
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	assert.Error(t, ValidateBreakWindows(entries, allowed, dur(0, 30)))
+
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	assert.NoError(t, ValidateBreakWindows(entries, allowed, dur(0, 30)))
+}
+
+func TestCheckBreakComplianceTrips(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(8, 30)},
+		{Type: EntryTypeCome, Time: tim(14, 30)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(16, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 0), result.TripTime)
+	ok, missing := CheckBreakCompliance(result, DefaultPolicy)
+	assert.False(t, ok)
+	assert.Equal(t, dur(0, 30), missing)
+
+	policy := DefaultPolicy
+	policy.TripsAsBreak = true
+	result, err = ComputeResult(entries, tim(16, 0), policy)
+	assert.NoError(t, err)
+	ok, _ = CheckBreakCompliance(result, policy)
+	assert.True(t, ok)
+}
+
+func TestComplianceScore(t *testing.T) {
+	day := func(comeAgain, leave time.Time) WorkTimeResult {
+		result, err := ComputeResult([]Entry{
+			{Type: EntryTypeCome, Time: tim(7, 0)},
+			{Type: EntryTypeLeave, Time: tim(12, 0)},
+			{Type: EntryTypeCome, Time: comeAgain},
+			{Type: EntryTypeLeave, Time: leave},
+		}, tim(22, 0), DefaultPolicy)
+		assert.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, 100, ComplianceScore(day(tim(12, 30), tim(15, 30)), dur(8, 0), DefaultPolicy))
+	// missing half of the break
+	assert.Equal(t, 80, ComplianceScore(day(tim(12, 15), tim(15, 45)), dur(8, 0), DefaultPolicy))
+	// exceeding the maximum work time with two hours of overtime
+	assert.Equal(t, 55, ComplianceScore(day(tim(12, 45), tim(18, 45)), dur(8, 0), DefaultPolicy))
+	// three quarters of the target
+	assert.Equal(t, 92, ComplianceScore(day(tim(12, 30), tim(13, 30)), dur(8, 0), DefaultPolicy))
+}
+
+func TestBreakDeficitCurve(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(13, 30)},
+		{Type: EntryTypeCome, Time: tim(13, 45)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	samples, err := BreakDeficitCurve(entries, 30*time.Minute, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Len(t, samples, 21)
+	deficits := make(map[time.Time]time.Duration, len(samples))
+	for _, sample := range samples {
+		deficits[sample.Time] = sample.Value
+	}
+	assert.Zero(t, deficits[tim(13, 0)])
+	// 6h threshold passed
+	assert.Equal(t, dur(0, 30), deficits[tim(13, 30)])
+	// 15 minutes of break taken
+	assert.Equal(t, dur(0, 15), deficits[tim(14, 0)])
+	assert.Equal(t, dur(0, 15), deficits[tim(16, 0)])
+	// 9h threshold passed
+	assert.Equal(t, dur(0, 30), deficits[tim(16, 30)])
+	assert.Equal(t, Sample{Time: tim(17, 0), Value: dur(0, 30)}, samples[len(samples)-1])
+
+	samples, err = BreakDeficitCurve(entries[:1], time.Hour, tim(9, 30), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []Sample{{Time: tim(7, 0)}, {Time: tim(8, 0)}, {Time: tim(9, 0)}, {Time: tim(9, 30)}}, samples)
+
+	_, err = BreakDeficitCurve(entries, 0, tim(18, 0), DefaultPolicy)
+	assert.Error(t, err)
+}
+
+func TestCheckContinuousWork(t *testing.T) {
+	policy := DefaultPolicy
+	policy.MaxContinuousWork = dur(6, 0)
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 45)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	err := CheckContinuousWork(entries, tim(18, 0), policy)
+	assert.ErrorIs(t, err, ErrContinuousWorkExceeded)
+	assert.Contains(t, err.Error(), "07:00")
+
+	_, err = ComputeStrict(entries, policy)
+	assert.ErrorIs(t, err, ErrContinuousWorkExceeded)
+
+	assert.NoError(t, CheckContinuousWork(entries, tim(18, 0), DefaultPolicy))
+
+	entries[1].Time = tim(14, 0)
+	entries[2].Time = tim(14, 45)
+	assert.NoError(t, CheckContinuousWork(entries, tim(18, 0), policy))
+
+	// open segments end at now
+	assert.ErrorIs(t, CheckContinuousWork(entries[:3], tim(21, 0), policy), ErrContinuousWorkExceeded)
+}
+
+func TestMonthlyBreakViolations(t *testing.T) {
+	day := func(d int, leave, come, end time.Time) []Entry {
+		return []Entry{
+			{Type: EntryTypeCome, Time: tim(8, 0).AddDate(0, 0, d)},
+			{Type: EntryTypeLeave, Time: leave.AddDate(0, 0, d)},
+			{Type: EntryTypeCome, Time: come.AddDate(0, 0, d)},
+			{Type: EntryTypeLeave, Time: end.AddDate(0, 0, d)},
+		}
+	}
+
+	days := map[time.Time][]Entry{
+		date(2019, time.November, 4): day(3, tim(12, 0), tim(12, 30), tim(17, 0)),
+		date(2019, time.November, 5): day(4, tim(12, 0), tim(12, 15), tim(17, 0)),
+		date(2019, time.November, 6): day(5, tim(12, 0), tim(12, 10), tim(13, 0)),
+		date(2019, time.November, 1): day(0, tim(12, 0), tim(12, 20), tim(18, 0)),
+		date(2019, time.November, 7): nil,
+	}
+
+	violations, err := MonthlyBreakViolations(days, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{date(2019, time.November, 1), date(2019, time.November, 5)}, violations)
+}
+
+func TestBreakComplianceSeries(t *testing.T) {
+	day := func(d int, come, end time.Time) []Entry {
+		return []Entry{
+			{Type: EntryTypeCome, Time: tim(8, 0).AddDate(0, 0, d)},
+			{Type: EntryTypeLeave, Time: tim(12, 0).AddDate(0, 0, d)},
+			{Type: EntryTypeCome, Time: come.AddDate(0, 0, d)},
+			{Type: EntryTypeLeave, Time: end.AddDate(0, 0, d)},
+		}
+	}
+
+	days := map[time.Time][]Entry{
+		date(2019, time.November, 1): day(0, tim(12, 20), tim(18, 0)),
+		date(2019, time.November, 2): nil,
+		date(2019, time.November, 4): day(3, tim(12, 30), tim(17, 0)),
+		date(2019, time.November, 5): day(4, tim(12, 15), tim(17, 0)),
+		date(2019, time.November, 6): day(5, tim(12, 10), tim(13, 0)),
+	}
+
+	series, err := BreakComplianceSeries(days, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Time]bool{
+		date(2019, time.November, 1): false,
+		date(2019, time.November, 2): true,
+		date(2019, time.November, 4): true,
+		date(2019, time.November, 5): false,
+		date(2019, time.November, 6): true,
+	}, series)
+
+	days[date(2019, time.November, 7)] = []Entry{{Type: EntryTypeLeave, Time: tim(8, 0)}}
+	_, err = BreakComplianceSeries(days, DefaultPolicy)
+	assert.Error(t, err)
+}
+
+func TestBreakAdequacyWithPlan(t *testing.T) {
+	ok, missing := BreakAdequacyWithPlan(dur(6, 30), 0, dur(0, 30), dur(2, 0), DefaultPolicy)
+	assert.True(t, ok)
+	assert.Equal(t, dur(0, 0), missing)
+
+	ok, missing = BreakAdequacyWithPlan(dur(6, 30), 0, dur(0, 20), dur(3, 0), DefaultPolicy)
+	assert.False(t, ok)
+	assert.Equal(t, dur(0, 25), missing)
+}
+
+func TestBreakPlacementWindow(t *testing.T) {
+	policy := DefaultPolicy
+	policy.EarliestCountableBreak = dur(0, 30)
+	rule := policy.BreakRules[0]
+
+	earliest, latest := BreakPlacementWindow(tim(8, 0), rule.WorkTime, rule.BreakTime, policy)
+	assert.Equal(t, tim(8, 30), earliest)
+	assert.Equal(t, tim(13, 30), latest)
+
+	earliest, latest = BreakPlacementWindow(tim(8, 0), rule.WorkTime, rule.BreakTime, DefaultPolicy)
+	assert.Equal(t, tim(8, 0), earliest)
+	assert.Equal(t, tim(13, 30), latest)
+}
+
+func TestBreakTimeliness(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	lateness, err := BreakTimeliness(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 0), lateness)
+
+	entries[1].Time = tim(12, 0)
+	entries[2].Time = tim(12, 30)
+	lateness, err = BreakTimeliness(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, -dur(2, 30), lateness)
+
+	lateness, err = BreakTimeliness(entries[:2], DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 0), lateness)
+
+	entries[2].Time = tim(12, 10)
+	_, err = BreakTimeliness(entries, DefaultPolicy)
+	assert.Error(t, err)
+}
+
+func TestBreakToMakeCompliant(t *testing.T) {
+	breakToAdd := func(workTime, breakTime time.Duration) time.Duration {
+		toAdd, _ := BreakToMakeCompliant(WorkTimeResult{WorkTime: workTime, BreakTime: breakTime}, 0, DefaultPolicy)
+		return toAdd
+	}
+
+	// exactly 9h of work only mandate 30 minutes, as the thresholds must be exceeded
+	assert.Equal(t, dur(0, 15), breakToAdd(dur(9, 0), dur(0, 15)))
+	// 15 minutes of added break reduce the work time to 9h instead of adding 30 minutes for the 9h rule
+	assert.Equal(t, dur(0, 15), breakToAdd(dur(9, 15), dur(0, 15)))
+	assert.Equal(t, dur(0, 30), breakToAdd(dur(9, 45), dur(0, 15)))
+	assert.Equal(t, dur(0, 5), breakToAdd(dur(6, 5), dur(0, 0)))
+	assert.Equal(t, dur(0, 0), breakToAdd(dur(8, 0), dur(0, 30)))
+
+	policy := DefaultPolicy
+	policy.BreakBasedOnPresence = true
+	toAdd, _ := BreakToMakeCompliant(WorkTimeResult{WorkTime: dur(9, 0), BreakTime: dur(0, 15)}, 0, policy)
+	assert.Equal(t, dur(0, 30), toAdd)
+}
+
+func TestBreakToMakeCompliantShortfall(t *testing.T) {
+	result := WorkTimeResult{WorkTime: dur(8, 20), BreakTime: dur(0, 10)}
+	toAdd, shortfall := BreakToMakeCompliant(result, dur(8, 0), DefaultPolicy)
+	assert.Equal(t, dur(0, 20), toAdd)
+	assert.Zero(t, shortfall)
+
+	toAdd, shortfall = BreakToMakeCompliant(result, dur(8, 15), DefaultPolicy)
+	assert.Equal(t, dur(0, 20), toAdd)
+	assert.Equal(t, dur(0, 15), shortfall)
+}
+
+func TestRequireContiguousBreak(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 15)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 15)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 15)},
+		{Type: EntryTypeLeave, Time: tim(18, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(19, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(9, 15), result.AccountedWorkTime)
+	ok, _ := CheckBreakCompliance(result, DefaultPolicy)
+	assert.True(t, ok)
+
+	policy := DefaultPolicy
+	policy.RequireContiguousBreak = true
+	result, err = ComputeResult(entries, tim(19, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 15), result.LongestBreak)
+	assert.Equal(t, dur(9, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(1, 0), result.AccountedBreakTime)
+	ok, missing := CheckBreakCompliance(result, policy)
+	assert.False(t, ok)
+	assert.Equal(t, dur(0, 30), missing)
+}
+
+func TestLiveBreakStatus(t *testing.T) {
+	entries := []Entry{{Type: EntryTypeCome, Time: tim(8, 0)}}
+
+	status, err := LiveBreakStatus(entries, tim(13, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Owed: 0, Deadline: tim(14, 0), Compliant: true}, status)
+
+	status, err = LiveBreakStatus(entries, tim(14, 5), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Owed: dur(0, 30), Deadline: tim(14, 0), Compliant: false}, status)
+
+	entries = append(entries, Entry{Type: EntryTypeLeave, Time: tim(12, 0)}, Entry{Type: EntryTypeCome, Time: tim(12, 30)})
+	status, err = LiveBreakStatus(entries, tim(15, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, BreakStatus{Owed: 0, Deadline: tim(17, 30), Compliant: true}, status)
+}
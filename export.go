@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type exportedDay struct {
+	Entries []exportedEntry `json:"entries"`
+	Result  exportedResult  `json:"result"`
+}
+
+type exportedEntry struct {
+	Type   EntryType `json:"type"`
+	Time   string    `json:"time"`
+	Source string    `json:"source,omitempty"`
+	Edited bool      `json:"edited,omitempty"`
+}
+
+type exportedResult struct {
+	Start              string `json:"start"`
+	End                string `json:"end"`
+	WorkTime           string `json:"workTime"`
+	BreakTime          string `json:"breakTime"`
+	AccountedWorkTime  string `json:"accountedWorkTime"`
+	AccountedBreakTime string `json:"accountedBreakTime"`
+}
+
+// ExportDay returns a JSON document containing the entries and computed result of a day.
+//
+// All times are formatted as RFC 3339 in loc and durations as "15:04". A nil loc keeps the location of each time.
+func ExportDay(entries []Entry, result WorkTimeResult, loc *time.Location) ([]byte, error) {
+	day := exportedDay{
+		Entries: make([]exportedEntry, len(entries)),
+		Result: exportedResult{
+			Start:              formatExportTime(result.Start, loc),
+			End:                formatExportTime(result.End, loc),
+			WorkTime:           formatDurationMinutes(result.WorkTime),
+			BreakTime:          formatDurationMinutes(result.BreakTime),
+			AccountedWorkTime:  formatDurationMinutes(result.AccountedWorkTime),
+			AccountedBreakTime: formatDurationMinutes(result.AccountedBreakTime),
+		},
+	}
+	for i, entry := range entries {
+		day.Entries[i] = exportedEntry{
+			Type:   entry.Type,
+			Time:   formatExportTime(entry.Time, loc),
+			Source: entry.Source,
+			Edited: entry.Edited,
+		}
+	}
+	return json.Marshal(&day)
+}
+
+func formatExportTime(t time.Time, loc *time.Location) string {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportDay(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 30), Source: "web"},
+	}
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+
+	data, err := ExportDay(entries, result, time.FixedZone("CET", 60*60))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"entries": [
+			{"type": "come", "time": "2019-11-01T08:00:00+01:00"},
+			{"type": "leave", "time": "2019-11-01T16:30:00+01:00", "source": "web"}
+		],
+		"result": {
+			"start": "2019-11-01T08:00:00+01:00",
+			"end": "2019-11-01T16:30:00+01:00",
+			"workTime": "08:30",
+			"breakTime": "00:00",
+			"accountedWorkTime": "08:00",
+			"accountedBreakTime": "00:30"
+		}
+	}`, string(data))
+}
+
+func TestExportDayNilLocation(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 30)},
+	}
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+
+	data, err := ExportDay(entries, result, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"start":"2019-11-01T07:00:00Z"`)
+	assert.Contains(t, string(data), `{"type":"leave","time":"2019-11-01T15:30:00Z"}`)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Interval describes a time span between two points in time.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// Overlap returns the duration both intervals have in common.
+func (i Interval) Overlap(other Interval) time.Duration {
+	start := i.Start
+	if other.Start.After(start) {
+		start = other.Start
+	}
+	end := i.End
+	if other.End.Before(end) {
+		end = other.End
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// breakIntervals returns all breaks between a leave and the following come entry.
+func breakIntervals(entries []Entry) []Interval {
+	var breaks []Interval
+	for i := 0; i+1 < len(entries); i++ {
+		if entries[i].Type == EntryTypeLeave && entries[i+1].Type == EntryTypeCome {
+			breaks = append(breaks, Interval{Start: entries[i].Time, End: entries[i+1].Time})
+		}
+	}
+	return breaks
+}
+
+// tripIntervals returns all trips from a trip to the following come entry.
+func tripIntervals(entries []Entry) []Interval {
+	var trips []Interval
+	for i := 0; i+1 < len(entries); i++ {
+		if entries[i].Type == EntryTypeTrip && entries[i+1].Type == EntryTypeCome {
+			trips = append(trips, Interval{Start: entries[i].Time, End: entries[i+1].Time})
+		}
+	}
+	return trips
+}
+
+// onCallIntervals returns all on-call spans from an on-call to the following come entry.
+func onCallIntervals(entries []Entry) []Interval {
+	var onCall []Interval
+	for i := 0; i+1 < len(entries); i++ {
+		if entries[i].Type == EntryTypeOnCall && entries[i+1].Type == EntryTypeCome {
+			onCall = append(onCall, Interval{Start: entries[i].Time, End: entries[i+1].Time})
+		}
+	}
+	return onCall
+}
+
+// workIntervals returns all working segments from a come to the following leave or on-call entry including trips.
+// An open segment ends at now.
+func workIntervals(entries []Entry, now time.Time) []Interval {
+	var work []Interval
+	var start time.Time
+	working := false
+	for _, entry := range entries {
+		if entry.Type == EntryTypeCome && !working {
+			start = entry.Time
+			working = true
+		} else if (entry.Type == EntryTypeLeave || entry.Type == EntryTypeOnCall) && working {
+			work = append(work, Interval{Start: start, End: entry.Time})
+			working = false
+		}
+	}
+	if working {
+		work = append(work, Interval{Start: start, End: now})
+	}
+	return work
+}
+
+// ComputeFromIntervals returns the accounted work and break times for separate lists of work and break intervals.
+func ComputeFromIntervals(work []Interval, breaks []Interval, policy Policy) (AccountedResult, error) {
+	all := append(append([]Interval{}, work...), breaks...)
+	for i := range all {
+		if all[i].End.Before(all[i].Start) {
+			return AccountedResult{}, fmt.Errorf("interval %s - %s ends before it starts", all[i].Start.Format("15:04"), all[i].End.Format("15:04"))
+		}
+		for j := i + 1; j < len(all); j++ {
+			if all[i].Overlap(all[j]) > 0 {
+				return AccountedResult{}, fmt.Errorf("interval %s - %s overlaps %s - %s", all[i].Start.Format("15:04"), all[i].End.Format("15:04"), all[j].Start.Format("15:04"), all[j].End.Format("15:04"))
+			}
+		}
+	}
+
+	var workTime, breakTime time.Duration
+	for _, i := range work {
+		workTime += i.Duration()
+	}
+	for _, i := range breaks {
+		breakTime += i.Duration()
+	}
+
+	accountedWorkTime, accountedBreakTime := policy.AccountedWorkTime(workTime, breakTime)
+	return AccountedResult{WorkTime: accountedWorkTime, BreakTime: accountedBreakTime}, nil
+}
+
+// MergeAdjacentSegments returns a copy of entries without leave and come pairs that are at most gap apart,
+// so that the surrounding working segments become one continuous segment.
+func MergeAdjacentSegments(entries []Entry, gap time.Duration) []Entry {
+	return mergeSegments(entries, func(d time.Duration) bool { return d <= gap })
+}
+
+// removeShortBreaks returns a copy of entries without leave and come pairs that are less than minBreak apart.
+func removeShortBreaks(entries []Entry, minBreak time.Duration) []Entry {
+	return mergeSegments(entries, func(d time.Duration) bool { return d < minBreak })
+}
+
+func mergeSegments(entries []Entry, merge func(gap time.Duration) bool) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for i := 0; i < len(entries); i++ {
+		if i+1 < len(entries) && entries[i].Type == EntryTypeLeave && entries[i+1].Type == EntryTypeCome && merge(entries[i+1].Time.Sub(entries[i].Time)) {
+			i++
+			continue
+		}
+		result = append(result, entries[i])
+	}
+	return result
+}
+
+// AccountInterval returns the accounted work and break times for a single presence interval containing breakTime of break.
+func AccountInterval(presence Interval, breakTime time.Duration, policy Policy) AccountedResult {
+	workTime, breakTime := policy.AccountedWorkTime(presence.Duration()-breakTime, breakTime)
+	return AccountedResult{WorkTime: workTime, BreakTime: breakTime}
+}
+
+// MeetingOverlap returns the part of the work time that overlaps with any of the meetings. Open days end at now.
+//
+// Time covered by multiple overlapping meetings is only counted once.
+func MeetingOverlap(entries []Entry, meetings []Interval, now time.Time) (time.Duration, error) {
+	if _, err := computeDayTimes(entries, now); err != nil {
+		return 0, err
+	}
+
+	sorted := slices.Clone(meetings)
+	slices.SortFunc(sorted, func(a, b Interval) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	var overlap time.Duration
+	for _, work := range workIntervals(entries, now) {
+		// meetings are sorted by start, so only the part after the previous meeting's end is new
+		covered := work.Start
+		for _, meeting := range sorted {
+			if meeting.Start.After(covered) {
+				covered = meeting.Start
+			}
+			overlap += Interval{Start: covered, End: meeting.End}.Overlap(work)
+			if meeting.End.After(covered) {
+				covered = meeting.End
+			}
+		}
+	}
+	return overlap, nil
+}
+
+// BreakStartTimes returns the time of each leave entry that starts a break. The final leave of the day is not included.
+func BreakStartTimes(entries []Entry) ([]time.Time, error) {
+	if _, err := computeDayTimes(entries, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var starts []time.Time
+	for _, b := range breakIntervals(entries) {
+		starts = append(starts, b.Start)
+	}
+	return starts, nil
+}
+
+// ComputeWindowed returns the result of the entries clipped to the window from to to. Work outside of the window is excluded.
+//
+// Working segments crossing the window borders are cut by virtual entries at from and to. Open days end at now.
+func ComputeWindowed(entries []Entry, from, to time.Time, now time.Time, policy Policy) (WorkTimeResult, error) {
+	if _, err := computeDayTimes(entries, now); err != nil {
+		return WorkTimeResult{}, err
+	}
+	return ComputeResult(clipEntries(entries, from, to, now), now, policy)
+}
+
+// clipEntries returns the entries between from and to. Segments crossing from or to are cut by virtual entries.
+func clipEntries(entries []Entry, from, to time.Time, now time.Time) []Entry {
+	clipped := make([]Entry, 0, len(entries)+4)
+	state := StateNone
+	i := 0
+	for ; i < len(entries) && !entries[i].Time.After(from); i++ {
+		state = entryState(entries[i].Type)
+	}
+	if state != StateNone {
+		clipped = append(clipped, Entry{Type: EntryTypeCome, Time: from})
+		if state == StateTrip {
+			clipped = append(clipped, Entry{Type: EntryTypeTrip, Time: from})
+		} else if state == StateOnCall {
+			clipped = append(clipped, Entry{Type: EntryTypeOnCall, Time: from})
+		}
+	}
+
+	for ; i < len(entries) && !entries[i].Time.After(to); i++ {
+		clipped = append(clipped, entries[i])
+		state = entryState(entries[i].Type)
+	}
+	// segments continuing after to are closed at to
+	if state != StateNone && (i < len(entries) || now.After(to)) {
+		if state != StateWorking {
+			clipped = append(clipped, Entry{Type: EntryTypeCome, Time: to})
+		}
+		clipped = append(clipped, Entry{Type: EntryTypeLeave, Time: to})
+	}
+	return clipped
+}
+
+// CoreHoursCoverage returns the fraction of the core hours spent working. Open days end at now.
+func CoreHoursCoverage(entries []Entry, core Interval, now time.Time) (float64, error) {
+	if _, err := computeDayTimes(entries, now); err != nil {
+		return 0, err
+	}
+	if core.Duration() <= 0 {
+		return 0, nil
+	}
+
+	var covered time.Duration
+	for _, work := range workIntervals(entries, now) {
+		covered += work.Overlap(core)
+	}
+	return float64(covered) / float64(core.Duration()), nil
+}
+
+// SegmentContribution describes the share of the accounted work time of a single working segment.
+type SegmentContribution struct {
+	Interval Interval
+	WorkTime time.Duration
+}
+
+// SegmentContributions returns the accounted work time of the day attributed to its working segments proportional to their length.
+// Open days end at now.
+//
+// Break deductions are thereby split across all segments. The segments are taken from the entries after snapping, rounding
+// and merging short breaks as done by ComputeResult. The contributions always sum up to the accounted work time.
+func SegmentContributions(entries []Entry, now time.Time, policy Policy) ([]SegmentContribution, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, now = preprocessEntries(entries, now, policy)
+	segments := workIntervals(entries, now)
+	var total time.Duration
+	for _, segment := range segments {
+		total += segment.Duration()
+	}
+
+	contributions := make([]SegmentContribution, len(segments))
+	var assigned time.Duration
+	for i, segment := range segments {
+		workTime := result.AccountedWorkTime - assigned
+		if i < len(segments)-1 && total > 0 {
+			workTime = time.Duration(float64(result.AccountedWorkTime) * float64(segment.Duration()) / float64(total))
+		}
+		contributions[i] = SegmentContribution{Interval: segment, WorkTime: workTime}
+		assigned += workTime
+	}
+	return contributions, nil
+}
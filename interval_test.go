@@ -0,0 +1,207 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntervalOverlap(t *testing.T) {
+	i := Interval{Start: tim(9, 0), End: tim(12, 0)}
+	assert.Equal(t, dur(3, 0), i.Duration())
+	assert.Equal(t, dur(1, 0), i.Overlap(Interval{Start: tim(11, 0), End: tim(13, 0)}))
+	assert.Equal(t, dur(3, 0), i.Overlap(Interval{Start: tim(8, 0), End: tim(13, 0)}))
+	assert.Equal(t, dur(0, 0), i.Overlap(Interval{Start: tim(12, 0), End: tim(13, 0)}))
+}
+
+func TestComputeFromIntervals(t *testing.T) {
+	work := []Interval{{Start: tim(8, 0), End: tim(12, 0)}, {Start: tim(12, 15), End: tim(17, 0)}}
+	breaks := []Interval{{Start: tim(12, 0), End: tim(12, 15)}}
+
+	result, err := ComputeFromIntervals(work, breaks, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 30), BreakTime: dur(0, 30)}, result)
+
+	breaks = []Interval{{Start: tim(11, 45), End: tim(12, 15)}}
+	_, err = ComputeFromIntervals(work, breaks, DefaultPolicy)
+	assert.Error(t, err)
+}
+
+func TestAccountInterval(t *testing.T) {
+	for _, end := range []time.Time{tim(13, 0), tim(14, 20), tim(17, 0), tim(19, 30)} {
+		presence := Interval{Start: tim(8, 0), End: end}
+		expected, err := ComputeAccountedFromEntries([]Entry{{Type: EntryTypeCome, Time: presence.Start}, {Type: EntryTypeLeave, Time: presence.End}})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, AccountInterval(presence, 0, DefaultPolicy))
+	}
+
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 30), BreakTime: dur(0, 30)}, AccountInterval(Interval{Start: tim(8, 0), End: tim(17, 0)}, dur(0, 10), DefaultPolicy))
+}
+
+func TestMergeAdjacentSegments(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+	}
+
+	merged := MergeAdjacentSegments(entries, 0)
+	assert.Equal(t, []Entry{entries[0], entries[3], entries[4], entries[5]}, merged)
+	assert.Len(t, breakIntervals(merged), 1)
+	assert.Len(t, entries, 6)
+}
+
+func TestMeetingOverlap(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	meetings := []Interval{
+		{Start: tim(16, 0), End: tim(18, 0)},
+		{Start: tim(7, 30), End: tim(8, 30)},
+		{Start: tim(11, 30), End: tim(13, 0)},
+		{Start: tim(12, 45), End: tim(13, 15)},
+	}
+
+	overlap, err := MeetingOverlap(entries, meetings, tim(19, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(2, 45), overlap)
+
+	overlap, err = MeetingOverlap(entries[:3], meetings, tim(14, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 45), overlap)
+
+	_, err = MeetingOverlap(nil, meetings, tim(14, 0))
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestBreakStartTimes(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 15)},
+		{Type: EntryTypeLeave, Time: tim(12, 30)},
+		{Type: EntryTypeCome, Time: tim(13, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	starts, err := BreakStartTimes(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{tim(10, 0), tim(12, 30)}, starts)
+
+	starts, err = BreakStartTimes(entries[:2])
+	assert.NoError(t, err)
+	assert.Empty(t, starts)
+
+	_, err = BreakStartTimes(entries[1:])
+	assert.Error(t, err)
+}
+
+func TestComputeWindowed(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 30)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeTrip, Time: tim(16, 0)},
+		{Type: EntryTypeCome, Time: tim(18, 0)},
+		{Type: EntryTypeLeave, Time: tim(19, 0)},
+	}
+
+	result, err := ComputeWindowed(entries, tim(9, 0), tim(17, 0), tim(20, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(9, 0), result.Start)
+	assert.Equal(t, tim(17, 0), result.End)
+	assert.Equal(t, dur(7, 30), result.WorkTime)
+	assert.Equal(t, dur(1, 0), result.TripTime)
+	assert.Equal(t, dur(0, 30), result.BreakTime)
+	assert.Equal(t, dur(7, 30), result.AccountedWorkTime)
+
+	result, err = ComputeWindowed(entries[:3], tim(9, 0), tim(17, 0), tim(14, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(14, 0), result.End)
+	assert.Equal(t, dur(4, 30), result.WorkTime)
+
+	result, err = ComputeWindowed(entries[:2], tim(9, 0), tim(10, 0), tim(14, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 0), result.WorkTime)
+
+	_, err = ComputeWindowed(entries, tim(20, 0), tim(21, 0), tim(22, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestCoreHoursCoverage(t *testing.T) {
+	core := Interval{Start: tim(10, 0), End: tim(15, 0)}
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(11, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 0)},
+		{Type: EntryTypeLeave, Time: tim(13, 30)},
+	}
+
+	coverage, err := CoreHoursCoverage(entries, core, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, coverage)
+
+	coverage, err = CoreHoursCoverage(entries[:3], core, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.8, coverage)
+
+	coverage, err = CoreHoursCoverage(entries, Interval{Start: tim(16, 0), End: tim(17, 0)}, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, coverage)
+
+	_, err = CoreHoursCoverage(nil, core, tim(18, 0))
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestSegmentContributions(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 10)},
+		{Type: EntryTypeLeave, Time: tim(16, 10)},
+	}
+
+	// 20 minutes of missing break are split 1:3
+	contributions, err := SegmentContributions(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []SegmentContribution{
+		{Interval: Interval{Start: tim(8, 0), End: tim(10, 0)}, WorkTime: dur(1, 55)},
+		{Interval: Interval{Start: tim(10, 10), End: tim(16, 10)}, WorkTime: dur(5, 45)},
+	}, contributions)
+
+	_, err = SegmentContributions(nil, tim(18, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestSegmentContributionsPreprocessed(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 3)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+	}
+
+	// the short break is merged into a single segment
+	policy := DefaultPolicy
+	policy.IgnoreBreaksBelow = 5 * time.Minute
+	contributions, err := SegmentContributions(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, []SegmentContribution{
+		{Interval: Interval{Start: tim(8, 0), End: tim(12, 0)}, WorkTime: dur(4, 0)},
+	}, contributions)
+
+	// open days end at now
+	contributions, err = SegmentContributions(entries[:3], tim(11, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []SegmentContribution{
+		{Interval: Interval{Start: tim(8, 0), End: tim(10, 0)}, WorkTime: dur(2, 0)},
+		{Interval: Interval{Start: tim(10, 3), End: tim(11, 0)}, WorkTime: dur(0, 57)},
+	}, contributions)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// LatestLegalLeave returns the time of day when the accounted work time reaches the maximum allowed work time.
+func LatestLegalLeave(start time.Time, breakTaken time.Duration, policy Policy) time.Time {
+	return start.Add(policy.MaxWorkTime).Add(max(breakTaken, policy.RequiredBreak(policy.MaxWorkTime)))
+}
+
+// GetLeaveTimes returns the minimal leave times for multiple target accounted work times.
+//
+// Targets above the maximum work time of the policy can not be reached and are omitted from the result.
+func GetLeaveTimes(start time.Time, breakTime time.Duration, targets []time.Duration, policy Policy) (map[time.Duration]time.Time, error) {
+	sortedTargets := make([]time.Duration, 0, len(targets))
+	for _, target := range targets {
+		if target <= policy.MaxWorkTime {
+			sortedTargets = append(sortedTargets, target)
+		}
+	}
+	slices.Sort(sortedTargets)
+
+	leaveTimes := make(map[time.Duration]time.Time, len(sortedTargets))
+	if len(sortedTargets) == 0 {
+		return leaveTimes, nil
+	}
+
+	// walk once through all work times and pick up targets as they are reached
+	for workTime := sortedTargets[0]; len(sortedTargets) > 0; workTime += time.Minute {
+		accountedWorkTime, accountedBreakTime := policy.AccountedWorkTime(workTime, breakTime)
+		for len(sortedTargets) > 0 && accountedWorkTime >= sortedTargets[0] {
+			leaveTimes[sortedTargets[0]] = start.Add(accountedWorkTime).Add(accountedBreakTime)
+			sortedTargets = sortedTargets[1:]
+		}
+	}
+	return leaveTimes, nil
+}
+
+// CompliantLeaveWindow returns the earliest leave time reaching minTarget and the latest leave time allowed by the policy.
+//
+// Both times are limited to the business hours on the day of start.
+func CompliantLeaveWindow(start time.Time, breakTaken, minTarget time.Duration, policy Policy) (earliest, latest time.Time, err error) {
+	earliest, err = getLeaveTime(start, breakTaken, minTarget, policy)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	latest = LatestLegalLeave(start, breakTaken, policy)
+
+	businessHours := policy.BusinessHours(start)
+	if earliest.Before(businessHours.Start) {
+		earliest = businessHours.Start
+	}
+	if latest.After(businessHours.End) {
+		latest = businessHours.End
+	}
+	if earliest.After(latest) {
+		return time.Time{}, time.Time{}, ErrOutOfBusinessHours
+	}
+	return earliest, latest, nil
+}
+
+func getLeaveTime(start time.Time, breakTime, target time.Duration, policy Policy) (time.Time, error) {
+	leaveTimes, err := GetLeaveTimes(start, breakTime, []time.Duration{target}, policy)
+	if err != nil {
+		return time.Time{}, err
+	}
+	leaveTime, ok := leaveTimes[target]
+	if !ok {
+		return time.Time{}, ErrMaxTimeReached
+	}
+	return leaveTime, nil
+}
+
+// EarliestComeUnderMax returns the earliest come time that keeps the work time until leave within the maximum work time.
+func EarliestComeUnderMax(leave time.Time, breakTime time.Duration, policy Policy) time.Time {
+	return leave.Add(-policy.MaxWorkTime).Add(-breakTime)
+}
+
+// ShortfallAtLeave returns how much accounted work time is missing to target when leaving at leave. It is zero if the target is met.
+func ShortfallAtLeave(start, leave time.Time, breakTime, target time.Duration, policy Policy) time.Duration {
+	accountedWorkTime, _ := policy.AccountedWorkTime(leave.Sub(start)-breakTime, breakTime)
+	return max(0, target-accountedWorkTime)
+}
+
+// TargetReachAt returns when the target is reached if the employee continues working from now without further break.
+//
+// Missing mandated break that is needed to reach the target is included in the returned time.
+func TargetReachAt(entries []Entry, target time.Duration, now time.Time, policy Policy) (time.Time, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getLeaveTime(result.Start, result.BreakTime, target, policy)
+}
+
+// ProjectedLeaveViolatesHours returns whether continuing to work from now until the target is reached ends after the
+// business hours of the day and the projected leave time.
+func ProjectedLeaveViolatesHours(entries []Entry, target time.Duration, now time.Time, policy Policy) (bool, time.Time, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	leave, err := getLeaveTime(result.Start, result.BreakTime, target, policy)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	return leave.After(policy.BusinessHours(result.Start).End), leave, nil
+}
+
+// CapReachedAt returns the time of day when the accounted work time reached the maximum work time and whether it was reached at all.
+func CapReachedAt(entries []Entry, policy Policy) (time.Time, bool, error) {
+	times, err := computeDayTimes(entries, time.Now())
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var workTime time.Duration
+	for _, work := range workIntervals(entries, times.End) {
+		breakTime := work.Start.Sub(times.Start) - workTime
+		for _, onCall := range onCallIntervals(entries) {
+			breakTime -= onCall.Overlap(Interval{Start: times.Start, End: work.Start})
+		}
+		capWorkTime := minWorkTimeForAccounted(policy.MaxWorkTime, breakTime, policy)
+		if workTime+work.Duration() >= capWorkTime {
+			return work.Start.Add(max(0, capWorkTime-workTime)), true, nil
+		}
+		workTime += work.Duration()
+	}
+	return time.Time{}, false, nil
+}
+
+// minWorkTimeForAccounted returns the minimal work time that results in the target accounted work time for a fixed break.
+func minWorkTimeForAccounted(target, breakTime time.Duration, policy Policy) time.Duration {
+	// the accounted work time is monotonic in the work time and missing break can not exceed the largest rule
+	low, high := target, target
+	for _, rule := range policy.BreakRules {
+		high = max(high, target+rule.BreakTime)
+	}
+	for low < high {
+		mid := low + (high-low)/2
+		if accountedWorkTime, _ := policy.AccountedWorkTime(mid, breakTime); accountedWorkTime >= target {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low
+}
+
+// EstimateBounds returns the accounted work time when leaving now and when staying as long as legally possible.
+//
+// Staying is limited by the maximum work time and the end of business hours. Closed days return the final accounted work time for both bounds.
+func EstimateBounds(entries []Entry, now time.Time, policy Policy) (min, max time.Duration, err error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return 0, 0, err
+	}
+	if entries[len(entries)-1].Type == EntryTypeLeave {
+		return result.AccountedWorkTime, result.AccountedWorkTime, nil
+	}
+
+	leave := LatestLegalLeave(result.Start, result.BreakTime, policy)
+	if businessEnd := policy.BusinessHours(result.Start).End; leave.After(businessEnd) {
+		leave = businessEnd
+	}
+	if leave.Before(now) {
+		leave = now
+	}
+	maxWorkTime, _ := policy.AccountedWorkTime(leave.Sub(result.Start)-result.BreakTime, result.BreakTime)
+	return result.AccountedWorkTime, maxWorkTime, nil
+}
+
+// ValidateLeaveTime returns ErrOutOfBusinessHours if leave is outside of the business hours of the policy.
+func ValidateLeaveTime(leave time.Time, policy Policy) error {
+	if !policy.InBusinessHours(leave) {
+		return ErrOutOfBusinessHours
+	}
+	return nil
+}
+
+// EntriesForMaxDay returns entries starting at start that result in exactly the maximum accounted work time of the policy.
+//
+// The break required at the maximum work time is inserted in the middle of the day. ErrOutOfBusinessHours is returned
+// if the resulting leave time is outside of the business hours.
+func EntriesForMaxDay(start time.Time, policy Policy) ([]Entry, error) {
+	result := AccountedResult{WorkTime: policy.MaxWorkTime, BreakTime: policy.RequiredBreak(policy.MaxWorkTime)}
+	entries := result.ToEntries(start, start)
+	if err := ValidateLeaveTime(entries[len(entries)-1].Time, policy); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// LeaveToReduceDeficit returns the leave time to reach the daily target and work off up to maxExtra of a deficit.
+//
+// The extra work time is limited to the deficit and the resulting target to the maximum work time of the policy.
+func LeaveToReduceDeficit(start time.Time, breakTime, dailyTarget, deficit, maxExtra time.Duration, policy Policy) (time.Time, error) {
+	target := min(dailyTarget+max(0, min(deficit, maxExtra)), policy.MaxWorkTime)
+	return getLeaveTime(start, breakTime, target, policy)
+}
+
+// TripWouldExceedMax returns true if a trip of tripDuration would push the accounted work time above the maximum work time.
+//
+// Trips do not add work time if the policy counts them as break.
+func TripWouldExceedMax(entries []Entry, tripDuration time.Duration, now time.Time, policy Policy) (bool, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return false, err
+	}
+	if policy.TripsAsBreak {
+		return false, nil
+	}
+	return result.WorkTime+tripDuration > minWorkTimeForAccounted(policy.MaxWorkTime, result.BreakTime, policy), nil
+}
+
+// RoundLeaveTime returns the earliest leave time at a multiple of granularity that reaches the target accounted work time.
+func RoundLeaveTime(start time.Time, breakTime, target time.Duration, granularity time.Duration, policy Policy) (time.Time, error) {
+	leave, err := getLeaveTime(start, breakTime, target, policy)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return RoundConfig{Granularity: granularity, Mode: RoundUp}.Round(leave), nil
+}
+
+// MaxReachableTarget returns the largest accounted work time that can still be reached today.
+//
+// Staying is limited by the maximum work time and the end of business hours. ErrOutOfBusinessHours is returned
+// if business hours are already over at now.
+func MaxReachableTarget(start time.Time, breakTaken time.Duration, now time.Time, policy Policy) (time.Duration, error) {
+	businessEnd := policy.BusinessHours(start).End
+	if now.After(businessEnd) {
+		return 0, ErrOutOfBusinessHours
+	}
+
+	leave := LatestLegalLeave(start, breakTaken, policy)
+	if leave.After(businessEnd) {
+		leave = businessEnd
+	}
+	workTime, _ := policy.AccountedWorkTime(leave.Sub(start)-breakTaken, breakTaken)
+	return max(0, workTime), nil
+}
+
+// OptimalArrival returns the earliest arrival within today's business hours and the leave time reaching the target with the mandated break.
+//
+// ErrMaxTimeReached is returned for targets above the maximum work time and ErrOutOfBusinessHours if the day does not fit into the business hours.
+func OptimalArrival(target time.Duration, policy Policy) (arrive, leave time.Time, err error) {
+	if target > policy.MaxWorkTime {
+		return time.Time{}, time.Time{}, ErrMaxTimeReached
+	}
+
+	businessHours := policy.BusinessHours(time.Now())
+	arrive = businessHours.Start
+	leave = arrive.Add(RequiredPresence(target, 0, policy))
+	if leave.After(businessHours.End) {
+		return time.Time{}, time.Time{}, ErrOutOfBusinessHours
+	}
+	return arrive, leave, nil
+}
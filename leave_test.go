@@ -0,0 +1,255 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestLegalLeave(t *testing.T) {
+	assert.Equal(t, tim(18, 45), LatestLegalLeave(tim(8, 0), dur(0, 30), DefaultPolicy))
+	assert.Equal(t, tim(19, 0), LatestLegalLeave(tim(8, 0), dur(1, 0), DefaultPolicy))
+
+	leave, err := GetLeaveTime(tim(8, 0), dur(0, 30), dur(10, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, leave, LatestLegalLeave(tim(8, 0), dur(0, 30), DefaultPolicy))
+}
+
+func TestGetLeaveTimes(t *testing.T) {
+	targets := []time.Duration{dur(10, 0), dur(6, 0), dur(8, 0), dur(10, 15)}
+	leaveTimes, err := GetLeaveTimes(tim(8, 0), dur(0, 15), targets, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, map[time.Duration]time.Time{
+		dur(6, 0):  tim(14, 15),
+		dur(8, 0):  tim(16, 30),
+		dur(10, 0): tim(18, 45),
+	}, leaveTimes)
+
+	for _, target := range targets[:3] {
+		leaveTime, err := GetLeaveTime(tim(8, 0), dur(0, 15), target)
+		assert.NoError(t, err)
+		assert.Equal(t, leaveTime, leaveTimes[target])
+	}
+}
+
+func TestCompliantLeaveWindow(t *testing.T) {
+	earliest, latest, err := CompliantLeaveWindow(tim(8, 0), dur(0, 30), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 30), earliest)
+	assert.Equal(t, tim(18, 45), latest)
+
+	earliest, latest, err = CompliantLeaveWindow(tim(11, 0), dur(0, 30), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(19, 30), earliest)
+	assert.Equal(t, tim(21, 0), latest)
+
+	_, _, err = CompliantLeaveWindow(tim(14, 0), dur(0, 30), dur(8, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrOutOfBusinessHours)
+
+	_, _, err = CompliantLeaveWindow(tim(8, 0), dur(0, 30), dur(10, 30), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrMaxTimeReached)
+}
+
+func TestEarliestComeUnderMax(t *testing.T) {
+	assert.Equal(t, tim(9, 15), EarliestComeUnderMax(tim(20, 0), dur(0, 45), DefaultPolicy))
+	assert.Equal(t, tim(20, 0), LatestLegalLeave(tim(9, 15), dur(0, 45), DefaultPolicy))
+}
+
+func TestShortfallAtLeave(t *testing.T) {
+	assert.Equal(t, dur(0, 12), ShortfallAtLeave(tim(8, 0), tim(16, 18), dur(0, 30), dur(8, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), ShortfallAtLeave(tim(8, 0), tim(16, 30), dur(0, 30), dur(8, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), ShortfallAtLeave(tim(8, 0), tim(17, 0), dur(0, 30), dur(8, 0), DefaultPolicy))
+}
+
+func TestTargetReachAt(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 15)},
+	}
+
+	reachAt, err := TargetReachAt(entries, dur(8, 0), tim(14, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 30), reachAt)
+
+	_, err = TargetReachAt(entries, dur(10, 30), tim(14, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrMaxTimeReached)
+}
+
+func TestProjectedLeaveViolatesHours(t *testing.T) {
+	entries := []Entry{{Type: EntryTypeCome, Time: tim(13, 0)}}
+
+	violates, leave, err := ProjectedLeaveViolatesHours(entries, dur(8, 0), tim(15, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, violates)
+	assert.Equal(t, tim(21, 30), leave)
+
+	entries[0].Time = tim(12, 30)
+	violates, leave, err = ProjectedLeaveViolatesHours(entries, dur(8, 0), tim(15, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.False(t, violates)
+	assert.Equal(t, tim(21, 0), leave)
+
+	_, _, err = ProjectedLeaveViolatesHours(entries, dur(10, 30), tim(15, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrMaxTimeReached)
+}
+
+func TestCapReachedAt(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(19, 30)},
+	}
+
+	capTime, ok, err := CapReachedAt(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, tim(18, 45), capTime)
+	assert.Equal(t, LatestLegalLeave(tim(8, 0), dur(0, 30), DefaultPolicy), capTime)
+
+	entries[3].Time = tim(17, 0)
+	_, ok, err = CapReachedAt(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestEstimateBounds(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+	}
+
+	minWork, maxWork, err := EstimateBounds(entries, tim(14, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 30), minWork)
+	assert.Equal(t, dur(10, 0), maxWork)
+
+	minWork, maxWork, err = EstimateBounds([]Entry{{Type: EntryTypeCome, Time: tim(12, 0)}}, tim(14, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(2, 0), minWork)
+	assert.Equal(t, dur(8, 30), maxWork)
+
+	minWork, maxWork, err = EstimateBounds(append(entries, Entry{Type: EntryTypeLeave, Time: tim(16, 30)}), tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), minWork)
+	assert.Equal(t, dur(8, 0), maxWork)
+}
+
+func TestValidateLeaveTime(t *testing.T) {
+	assert.NoError(t, ValidateLeaveTime(tim(17, 0), DefaultPolicy))
+	assert.NoError(t, ValidateLeaveTime(tim(21, 0), DefaultPolicy))
+	assert.ErrorIs(t, ValidateLeaveTime(tim(21, 30), DefaultPolicy), ErrOutOfBusinessHours)
+	assert.ErrorIs(t, ValidateLeaveTime(tim(6, 0), DefaultPolicy), ErrOutOfBusinessHours)
+}
+
+func TestEntriesForMaxDay(t *testing.T) {
+	entries, err := EntriesForMaxDay(tim(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4)
+	assert.Equal(t, tim(18, 45), entries[len(entries)-1].Time)
+
+	result, err := ComputeResult(entries, tim(20, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPolicy.MaxWorkTime, result.AccountedWorkTime)
+	assert.Equal(t, dur(0, 45), result.AccountedBreakTime)
+
+	_, err = EntriesForMaxDay(tim(11, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrOutOfBusinessHours)
+}
+
+func TestLeaveToReduceDeficit(t *testing.T) {
+	leave, err := LeaveToReduceDeficit(tim(8, 0), dur(0, 30), dur(8, 0), dur(2, 0), dur(1, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(17, 30), leave)
+
+	leave, err = LeaveToReduceDeficit(tim(8, 0), dur(0, 30), dur(8, 0), dur(0, 20), dur(1, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 50), leave)
+
+	leave, err = LeaveToReduceDeficit(tim(8, 0), dur(0, 30), dur(8, 0), dur(3, 0), dur(3, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(18, 45), leave)
+
+	leave, err = LeaveToReduceDeficit(tim(8, 0), dur(0, 30), dur(8, 0), -dur(1, 0), dur(1, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 30), leave)
+}
+
+func TestTripWouldExceedMax(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 45)},
+	}
+
+	exceeds, err := TripWouldExceedMax(entries, dur(0, 30), tim(17, 15), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.False(t, exceeds)
+
+	exceeds, err = TripWouldExceedMax(entries, dur(0, 30), tim(17, 25), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, exceeds)
+
+	policy := DefaultPolicy
+	policy.TripsAsBreak = true
+	exceeds, err = TripWouldExceedMax(entries, dur(0, 30), tim(17, 45), policy)
+	assert.NoError(t, err)
+	assert.False(t, exceeds)
+
+	_, err = TripWouldExceedMax(nil, dur(0, 30), tim(17, 45), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestRoundLeaveTime(t *testing.T) {
+	start := tim(8, 3)
+	exact, err := getLeaveTime(start, dur(0, 30), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 33), exact)
+
+	leave, err := RoundLeaveTime(start, dur(0, 30), dur(8, 0), 5*time.Minute, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 35), leave)
+	assert.Zero(t, ShortfallAtLeave(start, leave, dur(0, 30), dur(8, 0), DefaultPolicy))
+
+	leave, err = RoundLeaveTime(tim(8, 0), dur(0, 30), dur(8, 0), 5*time.Minute, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(16, 30), leave)
+
+	_, err = RoundLeaveTime(start, dur(0, 30), dur(11, 0), 5*time.Minute, DefaultPolicy)
+	assert.ErrorIs(t, err, ErrMaxTimeReached)
+}
+
+func TestMaxReachableTarget(t *testing.T) {
+	target, err := MaxReachableTarget(tim(8, 0), dur(0, 30), tim(12, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(10, 0), target)
+
+	target, err = MaxReachableTarget(tim(14, 0), 0, tim(15, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 30), target)
+
+	target, err = MaxReachableTarget(tim(14, 0), dur(0, 30), tim(15, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 30), target)
+
+	_, err = MaxReachableTarget(tim(14, 0), 0, tim(21, 30), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrOutOfBusinessHours)
+}
+
+func TestOptimalArrival(t *testing.T) {
+	arrive, leave, err := OptimalArrival(dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, "06:30", arrive.Format("15:04"))
+	assert.Equal(t, "15:00", leave.Format("15:04"))
+
+	policy := DefaultPolicy
+	policy.BusinessStart = dur(12, 0)
+	_, _, err = OptimalArrival(dur(9, 0), policy)
+	assert.ErrorIs(t, err, ErrOutOfBusinessHours)
+
+	_, _, err = OptimalArrival(dur(11, 0), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrMaxTimeReached)
+}
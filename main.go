@@ -14,7 +14,7 @@ import (
 var (
 	appMain             = kingpin.New("gohome", "Shows current worktime of the day and estimates flexi times.")
 	argLeaveTime        = appMain.Flag("leave", "Show statistics for a given leave time in format '15:04'").Short('l').String()
-	argTargetTime       = appMain.Flag("target-time", "Your daily target time like '08:00'").Short('t').String()
+	argTargetTime       = appMain.Flag("target-time", "Your daily target time like '08:00', '7.5h' or '7h30m'").Short('t').String()
 	argBreakTime        = appMain.Flag("break", "Ignore actual break time and take input like '00:45' instead").Short('b').String()
 	argReminder         = appMain.Flag("reminder", "Show desktop notification on target time").Short('r').Bool()
 	argVerbose          = appMain.Flag("verbose", "Print every single step").Short('v').Bool()
@@ -58,14 +58,13 @@ func process() error {
 
 	targetTime := time.Duration(8) * time.Hour
 	if len(*argTargetTime) > 0 {
-		t, err := time.Parse("15:04", *argTargetTime)
+		targetTime, err = ParseTarget(*argTargetTime)
 		if err != nil {
 			return fmt.Errorf("failed to parse target time: %s", err.Error())
 		}
-		targetTime = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
 		//TODO check target time
 
-		usrConf.TargetTimeStr = fmt.Sprintf("%02d:%02d", t.Hour(), t.Minute())
+		usrConf.TargetTimeStr = formatDurationMinutes(targetTime)
 	}
 
 	if *argSaveConfig {
@@ -137,13 +136,13 @@ func process() error {
 		currentState = entries[len(entries)-1].Type
 
 		if len(*argLeaveTime) > 0 {
-			t, err := time.Parse("15:04", *argLeaveTime)
+			leaveTime, err := ParseTime(*argLeaveTime, ParseOptions{OnDate: time.Now()})
 			if err != nil {
 				return fmt.Errorf("failed to parse leave time: %s", err.Error())
 			}
-			now := time.Now()
-			leaveTime := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
-			//TODO check leaveTime
+			if err := ValidateLeaveTime(leaveTime, DefaultPolicy); err != nil {
+				stdio.Warn("leave time %s: %s", leaveTime.Format("15:04"), err.Error())
+			}
 			entries = append(entries, Entry{Type: EntryTypeLeave, Time: leaveTime})
 		}
 
@@ -154,30 +153,17 @@ func process() error {
 				stdio.Println(" %s<-- %s%s", colors.LeaveEntry, entry.Time.Format("15:04"), colorEnd)
 			} else if entry.Type == EntryTypeTrip {
 				stdio.Println(" %s<-- %s DG%s", colors.TripEntry, entry.Time.Format("15:04"), colorEnd)
+			} else if entry.Type == EntryTypeOnCall {
+				stdio.Println(" %s<-- %s RB%s", colors.TripEntry, entry.Time.Format("15:04"), colorEnd)
 			}
 		}
 
-		workTime, startTime, breakTime, err := ComputeWorkTime(entries)
-		if err != nil {
-			return err
-		}
-
-		if len(*argBreakTime) > 0 {
-			t, err := time.Parse("15:04", *argBreakTime)
-			if err != nil {
-				return fmt.Errorf("failed to parse break time: %s", err.Error())
-			}
-
-			newBreakTime := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
-			diff := (breakTime - newBreakTime)
-			breakTime = newBreakTime
-			workTime += diff
-		}
-
-		accountedWorkTime, accountedBreakTime, err := ComputeAccountedWorkTime(workTime, breakTime)
+		result, err := computeDisplayResult(entries, *argBreakTime, time.Now())
 		if err != nil {
 			return err
 		}
+		startTime, breakTime := result.Start, result.BreakTime
+		accountedWorkTime, accountedBreakTime := result.AccountedWorkTime, result.AccountedBreakTime
 
 		stdio.Println("-----------------------------------------------------")
 		if cacheOK {
@@ -241,6 +227,29 @@ func process() error {
 	return nil
 }
 
+// computeDisplayResult returns the result shown by the command line. A non-empty breakOverride like '00:45'
+// replaces the taken break, moving the difference to the work time.
+func computeDisplayResult(entries []Entry, breakOverride string, now time.Time) (WorkTimeResult, error) {
+	result, err := ComputeResult(entries, now, DefaultPolicy)
+	if err != nil {
+		return WorkTimeResult{}, err
+	}
+
+	if len(breakOverride) > 0 {
+		t, err := time.Parse("15:04", breakOverride)
+		if err != nil {
+			return WorkTimeResult{}, fmt.Errorf("failed to parse break time: %s", err.Error())
+		}
+
+		newBreakTime := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		diff := (result.BreakTime - newBreakTime)
+		result.BreakTime = newBreakTime
+		result.WorkTime += diff
+		result.AccountedWorkTime, result.AccountedBreakTime = DefaultPolicy.AccountedWorkTime(result.WorkTime, result.BreakTime)
+	}
+	return result, nil
+}
+
 func noSeconds(t time.Duration) time.Duration {
 	return time.Duration(int(t.Minutes())) * time.Minute
 }
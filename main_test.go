@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDisplayResultOnCall(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeOnCall, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(16, 0)},
+		{Type: EntryTypeLeave, Time: tim(18, 0)},
+	}
+
+	result, err := computeDisplayResult(entries, "", tim(19, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 0), result.Start)
+	assert.Equal(t, dur(6, 0), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, dur(6, 0), result.AccountedWorkTime)
+	assert.Zero(t, result.AccountedBreakTime)
+
+	result, err = computeDisplayResult(entries, "00:30", tim(19, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 30), result.WorkTime)
+	assert.Equal(t, dur(0, 30), result.BreakTime)
+	assert.Equal(t, dur(5, 30), result.AccountedWorkTime)
+
+	_, err = computeDisplayResult(entries, "half an hour", tim(19, 0))
+	assert.Error(t, err)
+}
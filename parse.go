@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseOptions controls how ParseTime interprets incomplete values.
+type ParseOptions struct {
+	// OnDate is combined with values that only contain a time of day like "09:10".
+	OnDate time.Time
+}
+
+// ParseTime parses a full RFC 3339 timestamp or a time of day in format "15:04".
+//
+// Time of day values are placed on opts.OnDate in its location.
+func ParseTime(str string, opts ParseOptions) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, str); err == nil {
+		return t, nil
+	}
+
+	tod, err := time.Parse("15:04", str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %s", str, err.Error())
+	}
+	if opts.OnDate.IsZero() {
+		return time.Time{}, fmt.Errorf("time %q has no date", str)
+	}
+	return CombineDate(opts.OnDate, tod), nil
+}
+
+// CombineDate returns the time of day of tod on the given date in the location of date.
+func CombineDate(date time.Time, tod time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), tod.Hour(), tod.Minute(), tod.Second(), tod.Nanosecond(), date.Location())
+}
+
+// ParseTarget parses a target work time like "07:30", decimal hours like "7.5" or "7.5h", or a duration like "7h30m" or "450m".
+//
+// Negative targets and targets of a day or more are rejected.
+func ParseTarget(str string) (time.Duration, error) {
+	if t, err := time.Parse("15:04", str); err == nil {
+		return timeOfDay(t), nil
+	}
+	var d time.Duration
+	if hours, err := strconv.ParseFloat(str, 64); err == nil {
+		d = time.Duration(hours * float64(time.Hour))
+	} else if d, err = time.ParseDuration(str); err != nil {
+		return 0, fmt.Errorf("failed to parse target %q", str)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("target %q is negative", str)
+	}
+	if d >= 24*time.Hour {
+		return 0, fmt.Errorf("target %q exceeds a day", str)
+	}
+	return d, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	onDate := time.Date(2019, time.November, 1, 0, 0, 0, 0, loc)
+
+	parsed, err := ParseTime("09:10", ParseOptions{OnDate: onDate})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.November, 1, 8, 10, 0, 0, time.UTC), parsed.UTC())
+	assert.Equal(t, loc, parsed.Location())
+
+	parsed, err = ParseTime("2019-11-01T09:10:00Z", ParseOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, time.November, 1, 9, 10, 0, 0, time.UTC), parsed)
+
+	_, err = ParseTime("09:10", ParseOptions{})
+	assert.Error(t, err)
+	_, err = ParseTime("9 o'clock", ParseOptions{OnDate: onDate})
+	assert.Error(t, err)
+}
+
+func TestParseTarget(t *testing.T) {
+	for _, str := range []string{"7.5h", "7h30m", "450m", "7.5", "07:30"} {
+		target, err := ParseTarget(str)
+		assert.NoError(t, err, str)
+		assert.Equal(t, dur(7, 30), target, str)
+	}
+
+	_, err := ParseTarget("seven hours")
+	assert.Error(t, err)
+	_, err = ParseTarget("-1h")
+	assert.Error(t, err)
+	_, err = ParseTarget("-7.5")
+	assert.Error(t, err)
+
+	// targets are stored as '15:04' and must be readable again
+	for _, str := range []string{"25", "24h", "1440m"} {
+		_, err = ParseTarget(str)
+		assert.Error(t, err, str)
+	}
+	target, err := ParseTarget("23.5")
+	assert.NoError(t, err)
+	target, err = ParseTarget(formatDurationMinutes(target))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(23, 30), target)
+}
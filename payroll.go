@@ -0,0 +1,100 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// PayrollTruncate truncates entry times to full minutes, or the accounted times if break rules were already applied.
+	PayrollTruncate PayrollStep = iota
+	// PayrollBreakRules applies the break rules and the remaining accounting of the policy to the work and break time
+	// the same way as ComputeResult.
+	PayrollBreakRules
+	// PayrollRound rounds the work time according to the payroll rounding of the policy.
+	PayrollRound
+)
+
+// PayrollStep denotes a single step of the payroll computation.
+type PayrollStep int
+
+// DefaultPayrollSteps truncates to minutes, applies the break rules and rounds the resulting work time.
+var DefaultPayrollSteps = []PayrollStep{PayrollTruncate, PayrollBreakRules, PayrollRound}
+
+// PayrollResult returns the accounted times as computed by payroll, performing the payroll steps of the policy in order.
+//
+// DefaultPayrollSteps are used if the policy does not define any steps. Work time removed by rounding is not added to the break.
+func PayrollResult(entries []Entry, policy Policy) (AccountedResult, error) {
+	steps := policy.PayrollSteps
+	if steps == nil {
+		steps = DefaultPayrollSteps
+	}
+
+	now := time.Now()
+	entries = slices.Clone(entries)
+	var workTime, breakTime, longestBreak, tripCredit time.Duration
+	computed := false
+	compute := func() error {
+		if computed {
+			return nil
+		}
+		result, err := ComputeResult(entries, now, policy)
+		if err != nil {
+			return err
+		}
+		workTime, breakTime, longestBreak, tripCredit = result.WorkTime, result.BreakTime, result.LongestBreak, result.TripCredit
+		computed = true
+		return nil
+	}
+
+	for _, step := range steps {
+		switch step {
+		case PayrollTruncate:
+			if computed {
+				workTime, breakTime, longestBreak = noSeconds(workTime), noSeconds(breakTime), noSeconds(longestBreak)
+				continue
+			}
+			for i := range entries {
+				entries[i].Time = entries[i].Time.Truncate(time.Minute)
+			}
+			now = now.Truncate(time.Minute)
+		case PayrollBreakRules:
+			if err := compute(); err != nil {
+				return AccountedResult{}, err
+			}
+			workTime, breakTime, _ = accountTimes(workTime, breakTime, longestBreak, tripCredit, policy)
+		case PayrollRound:
+			if err := compute(); err != nil {
+				return AccountedResult{}, err
+			}
+			workTime = policy.PayrollRounding.RoundDuration(workTime)
+		}
+	}
+
+	if err := compute(); err != nil {
+		return AccountedResult{}, err
+	}
+	return AccountedResult{WorkTime: workTime, BreakTime: breakTime}, nil
+}
+
+// ComputeHalfHour returns the payroll result with the work time rounded to half-hours.
+//
+// The rounding mode is taken from the payroll rounding of the policy, which rounds to the nearest half-hour by default.
+func ComputeHalfHour(entries []Entry, policy Policy) (AccountedResult, error) {
+	policy.PayrollRounding.Granularity = 30 * time.Minute
+	return PayrollResult(entries, policy)
+}
+
+// PayMultipliers define the pay factors of overtime and excess work time relative to regular work time.
+type PayMultipliers struct {
+	Overtime float64
+	Excess   float64
+}
+
+// DefaultPayMultipliers pay overtime at 125 percent and excess work time at 150 percent.
+var DefaultPayMultipliers = PayMultipliers{Overtime: 1.25, Excess: 1.5}
+
+// WeightedHours returns the work time weighted by the pay multipliers of its bands as a quick cost estimate.
+func WeightedHours(regular, overtime, excess time.Duration, multipliers PayMultipliers) time.Duration {
+	return regular + time.Duration(float64(overtime)*multipliers.Overtime) + time.Duration(float64(excess)*multipliers.Excess)
+}
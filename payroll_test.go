@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayrollResult(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 58).Add(40 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(12, 1).Add(30 * time.Second)},
+		{Type: EntryTypeCome, Time: tim(12, 20).Add(10 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(16, 44).Add(50 * time.Second)},
+	}
+
+	policy := DefaultPolicy
+	policy.PayrollRounding = RoundConfig{Granularity: 15 * time.Minute, Mode: RoundDown}
+
+	// 07:58 - 12:01 and 12:20 - 16:44 are 08:27 of work with 00:19 of break,
+	// which is accounted as 08:16 with 00:30 of break and rounded down to 08:15
+	result, err := PayrollResult(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 15), BreakTime: dur(0, 30)}, result)
+
+	policy.PayrollSteps = []PayrollStep{PayrollBreakRules, PayrollTruncate}
+	result, err = PayrollResult(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 16), BreakTime: dur(0, 30)}, result)
+
+	policy.PayrollSteps = []PayrollStep{PayrollTruncate, PayrollRound, PayrollBreakRules}
+	result, err = PayrollResult(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 4), BreakTime: dur(0, 30)}, result)
+
+	_, err = PayrollResult(nil, policy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestPayrollResultMatchesComputeResult(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 15)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 15)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	policy := DefaultPolicy
+	policy.RequireContiguousBreak = true
+	policy.MaxCreditedBreak = dur(0, 40)
+	computed, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 45), computed.AccountedWorkTime)
+
+	result, err := PayrollResult(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: computed.AccountedWorkTime, BreakTime: computed.AccountedBreakTime}, result)
+
+	result, err = ComputeHalfHour(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.WorkTime)
+
+	policy = DefaultPolicy
+	policy.MinShiftLength = dur(3, 0)
+	result, err = PayrollResult(entries[:2], policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(3, 0), result.WorkTime)
+}
+
+func TestComputeHalfHour(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 17)},
+	}
+
+	result, err := ComputeHalfHour(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 0), BreakTime: dur(0, 30)}, result)
+
+	policy := DefaultPolicy
+	policy.PayrollRounding.Mode = RoundDown
+	result, err = ComputeHalfHour(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 30), result.WorkTime)
+
+	policy.PayrollRounding.Mode = RoundUp
+	result, err = ComputeHalfHour(entries, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.WorkTime)
+
+	entries[3].Time = tim(16, 14)
+	result, err = ComputeHalfHour(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 30), result.WorkTime)
+}
+
+func TestWeightedHours(t *testing.T) {
+	assert.Equal(t, dur(9, 15), WeightedHours(dur(8, 0), dur(1, 0), 0, DefaultPayMultipliers))
+	assert.Equal(t, dur(10, 45), WeightedHours(dur(8, 0), dur(1, 0), dur(1, 0), DefaultPayMultipliers))
+	assert.Equal(t, dur(10, 0), WeightedHours(dur(8, 0), dur(1, 0), 0, PayMultipliers{Overtime: 2}))
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// BreakRule requires a minimum break time as soon as the work time exceeds a threshold.
+type BreakRule struct {
+	WorkTime  time.Duration
+	BreakTime time.Duration
+}
+
+// Policy describes the country policies used to account work and break times.
+type Policy struct {
+	// BreakRules must be sorted by ascending work time.
+	BreakRules  []BreakRule
+	MaxWorkTime time.Duration
+	// MaxContinuousWork is the maximum length of a single working segment checked by CheckContinuousWork. Zero disables the check.
+	MaxContinuousWork time.Duration
+	// WeeklyMax is the maximum work time per week to be checked with CheckWeeklyMax.
+	WeeklyMax time.Duration
+	// BusinessStart and BusinessEnd denote the allowed working hours as offsets to midnight.
+	BusinessStart time.Duration
+	BusinessEnd   time.Duration
+	// ShiftStart is the official start of work as offset to midnight. Come entries within SnapWindow around it are
+	// moved to the shift start. A SnapWindow of zero disables snapping.
+	ShiftStart time.Duration
+	SnapWindow time.Duration
+	// RoundPresenceToMinute defines how partial minutes at the start and end of the presence are counted. RoundDown
+	// drops them, RoundUp counts them as full minutes and RoundNearest rounds both ends to the nearest minute.
+	// Nil leaves the presence untouched.
+	RoundPresenceToMinute *RoundMode
+	// SourceRounding defines how entry times are rounded depending on the entry source.
+	// Entries from sources not contained in the map are used as is.
+	SourceRounding map[string]RoundConfig
+	// IgnoreBreaksBelow defines a minimum break length. Shorter gaps between leave and come are
+	// considered punch corrections and the surrounding working segments are merged.
+	IgnoreBreaksBelow time.Duration
+	// BreakBasedOnPresence uses the presence time (work plus break) instead of the work time to determine
+	// the mandated break. Breaks taken thereby push the day over the thresholds, and a missing break is
+	// deducted completely instead of only reducing the work time down to the threshold.
+	BreakBasedOnPresence bool
+	// TripsAsBreak counts the time spent on trips as break instead of work.
+	TripsAsBreak bool
+	// MaxCreditedBreak limits the reported break time. Any break beyond is neither work nor credited break,
+	// but reported as excess break. Zero means no limit.
+	MaxCreditedBreak time.Duration
+	// FixedBreakWindows are always unpaid. Work within these windows is counted as break,
+	// while breaks already taken during a window are not deducted again. Trips within a window are not deducted either.
+	FixedBreakWindows []DailyWindow
+	// SlidingBreakAtThreshold only deducts as much missing break as the work time exceeds a break rule threshold.
+	// Working 6:05 without break is thereby accounted as 6:00 instead of 5:35.
+	SlidingBreakAtThreshold bool
+	// RequireContiguousBreak only accepts a single contiguous break to satisfy the mandated break.
+	// Shorter breaks are still not counted as work, but do not add up to the mandated break.
+	RequireContiguousBreak bool
+	// EarliestCountableBreak is the time after the first come before which breaks are counted as work.
+	EarliestCountableBreak time.Duration
+	// MinShiftLength guarantees a minimum accounted work time for days with entries, for example when being sent home early.
+	// The taken break is not reduced by the credited time. Zero disables the guarantee.
+	MinShiftLength time.Duration
+	// OnCallFactor is the fraction of on-call time credited as work, like 0.25 for a quarter.
+	// The remaining on-call time is neither work nor break.
+	OnCallFactor float64
+	// TripRoundGranularity rounds up the duration of each trip, for example to reimburse travel in blocks
+	// of 15 minutes. The additional trip time is credited as TripCredit on top of the accounted work time without
+	// reducing the break. Trips counted as break are not rounded. Zero disables rounding.
+	TripRoundGranularity time.Duration
+	// PayrollSteps defines the order of steps performed by PayrollResult. DefaultPayrollSteps are used if nil.
+	PayrollSteps []PayrollStep
+	// PayrollRounding defines how PayrollResult rounds the work time. The zero value does not round.
+	PayrollRounding RoundConfig
+	// RuleOrder defines the order in which AccountedWorkTime applies the break rules and the maximum work time.
+	// All break rules in their given order followed by the maximum work time are applied if nil.
+	//
+	// Capping the work time only moves time to the break and does not change the result. The order of break rules
+	// matters without SlidingBreakAtThreshold: applying the 9h rule first deducts its full break from 9:01 of work,
+	// while applying the 6h rule first reduces the work time below 9h, so that the 9h rule no longer applies.
+	RuleOrder []RuleKind
+	// DistributedBreak optionally requires a break of BreakTime after every WorkTime of work,
+	// to spread breaks over the day. It is only used by RequiredBreakSchedule.
+	DistributedBreak BreakRule
+}
+
+// RuleMaxWorkTime limits the work time to the maximum work time of the policy.
+const RuleMaxWorkTime RuleKind = -1
+
+// RuleKind denotes a step of the accounting. Non-negative values apply the break rule with this index in BreakRules.
+type RuleKind int
+
+// DailyWindow denotes a time span on each day as offsets to midnight.
+type DailyWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// On returns the window on the day of date.
+func (w DailyWindow) On(date time.Time) Interval {
+	day := midnight(date)
+	return Interval{Start: day.Add(w.Start), End: day.Add(w.End)}
+}
+
+// DefaultPolicy contains the German working time regulations.
+var DefaultPolicy = Policy{
+	BreakRules: []BreakRule{
+		{WorkTime: 6 * time.Hour, BreakTime: 30 * time.Minute},
+		{WorkTime: 9 * time.Hour, BreakTime: 45 * time.Minute},
+	},
+	MaxWorkTime:             10 * time.Hour,
+	WeeklyMax:               48 * time.Hour,
+	BusinessStart:           6*time.Hour + 30*time.Minute,
+	BusinessEnd:             21 * time.Hour,
+	SlidingBreakAtThreshold: true,
+}
+
+// RequiredBreak returns the mandated break time for a given work time.
+func (p Policy) RequiredBreak(workTime time.Duration) time.Duration {
+	var breakTime time.Duration
+	for _, rule := range p.BreakRules {
+		if workTime > rule.WorkTime && rule.BreakTime > breakTime {
+			breakTime = rule.BreakTime
+		}
+	}
+	return breakTime
+}
+
+// RequiredBreakFunc returns RequiredBreak as standalone function, for example to be used in templates.
+//
+// The break rules are copied, so later changes to the policy do not affect the returned function.
+func (p Policy) RequiredBreakFunc() func(time.Duration) time.Duration {
+	rules := Policy{BreakRules: slices.Clone(p.BreakRules)}
+	return rules.RequiredBreak
+}
+
+// RequiredBreakFor returns the mandated break time for a day with the given work and break time.
+//
+// In contrast to RequiredBreak, this respects BreakBasedOnPresence.
+func (p Policy) RequiredBreakFor(workTime, breakTime time.Duration) time.Duration {
+	if p.BreakBasedOnPresence {
+		return p.RequiredBreak(workTime + breakTime)
+	}
+	return p.RequiredBreak(workTime)
+}
+
+// BreakSchedule returns the effective break rules sorted by work time.
+//
+// Rules that never increase the required break, because an earlier rule already demands more, are omitted.
+func (p Policy) BreakSchedule() []BreakRule {
+	rules := slices.Clone(p.BreakRules)
+	slices.SortStableFunc(rules, func(a, b BreakRule) int {
+		return cmp.Compare(a.WorkTime, b.WorkTime)
+	})
+
+	schedule := make([]BreakRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(schedule) > 0 && rule.BreakTime <= schedule[len(schedule)-1].BreakTime {
+			continue
+		}
+		schedule = append(schedule, rule)
+	}
+	return schedule
+}
+
+// InBusinessHours returns true when t is within the allowed business working hours.
+func (p Policy) InBusinessHours(t time.Time) bool {
+	tod := timeOfDay(t)
+	return tod >= p.BusinessStart && tod <= p.BusinessEnd
+}
+
+// BusinessHours returns the allowed business working hours on the day of date.
+func (p Policy) BusinessHours(date time.Time) Interval {
+	return DailyWindow{Start: p.BusinessStart, End: p.BusinessEnd}.On(date)
+}
+
+// AccountedWorkTime returns the accounted work and break times according to the policy.
+func (p Policy) AccountedWorkTime(workTime, breakTime time.Duration) (time.Duration, time.Duration) {
+	for _, kind := range p.ruleOrder() {
+		if kind == RuleMaxWorkTime {
+			// are the corrected values still above max?
+			if workTime > p.MaxWorkTime {
+				breakTime = workTime + breakTime - p.MaxWorkTime
+				workTime = p.MaxWorkTime
+			}
+			continue
+		}
+		if kind < 0 || int(kind) >= len(p.BreakRules) {
+			continue
+		}
+
+		rule := p.BreakRules[kind]
+		if p.BreakBasedOnPresence {
+			if workTime+breakTime > rule.WorkTime && breakTime < rule.BreakTime {
+				workTime = workTime + breakTime - rule.BreakTime
+				breakTime = rule.BreakTime
+			}
+			continue
+		}
+
+		if workTime > rule.WorkTime && breakTime < rule.BreakTime {
+			if p.SlidingBreakAtThreshold && (workTime+breakTime-rule.WorkTime) < rule.BreakTime {
+				breakTime = workTime + breakTime - rule.WorkTime
+				workTime = rule.WorkTime
+			} else {
+				workTime = workTime + breakTime - rule.BreakTime
+				breakTime = rule.BreakTime
+			}
+		}
+	}
+
+	return workTime, breakTime
+}
+
+func (p Policy) ruleOrder() []RuleKind {
+	if p.RuleOrder != nil {
+		return p.RuleOrder
+	}
+	order := make([]RuleKind, 0, len(p.BreakRules)+1)
+	for i := range p.BreakRules {
+		order = append(order, RuleKind(i))
+	}
+	return append(order, RuleMaxWorkTime)
+}
+
+// MarginalBreakCost returns the additional mandated break caused by working extra time.
+func MarginalBreakCost(currentWork, breakTaken time.Duration, extra time.Duration, policy Policy) time.Duration {
+	missingBefore := max(0, policy.RequiredBreak(currentWork)-breakTaken)
+	missingAfter := max(0, policy.RequiredBreak(currentWork+extra)-breakTaken)
+	return missingAfter - missingBefore
+}
+
+// RequiredPresence returns the presence time needed to reach an accounted target work time with a given break.
+func RequiredPresence(targetWork, breakTime time.Duration, policy Policy) time.Duration {
+	return targetWork + max(breakTime, policy.RequiredBreak(targetWork))
+}
+
+// ScheduledVsMandatedBreak returns how much the scheduled break exceeds the mandated break for the work time.
+//
+// A negative result denotes a schedule that is too short to comply with the policy.
+func ScheduledVsMandatedBreak(scheduled time.Duration, workTime time.Duration, policy Policy) time.Duration {
+	return scheduled - policy.RequiredBreak(workTime)
+}
+
+// MinBreakForWork returns the single governing minimum break for workTime. Break rules do not add up, so the largest
+// applicable rule is returned, e.g. 45 minutes and not 75 minutes for more than 9 hours of work with the default policy.
+func MinBreakForWork(workTime time.Duration, policy Policy) time.Duration {
+	return policy.RequiredBreak(workTime)
+}
+
+// RequiredBreakRatio returns the mandated break time relative to the work time.
+func RequiredBreakRatio(workTime time.Duration, policy Policy) float64 {
+	if workTime <= 0 {
+		return 0
+	}
+	return float64(policy.RequiredBreak(workTime)) / float64(workTime)
+}
+
+// ComparePolicies returns the results of the entries under both policies and the difference of accounted work time from a to b.
+func ComparePolicies(entries []Entry, a, b Policy) (resultA, resultB WorkTimeResult, delta time.Duration, err error) {
+	now := time.Now()
+	resultA, err = ComputeResult(entries, now, a)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, err
+	}
+	resultB, err = ComputeResult(entries, now, b)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, err
+	}
+	return resultA, resultB, resultB.AccountedWorkTime - resultA.AccountedWorkTime, nil
+}
+
+// BreakRequirement describes a break that has to be taken after a certain work time.
+type BreakRequirement struct {
+	After  time.Duration
+	Amount time.Duration
+}
+
+// RequiredBreakSchedule returns when and how much break needs to be taken during the given work time.
+//
+// Policies with a distributed break require its amount after every interval. Otherwise, the break rules are
+// listed with the additional break each rule requires.
+func RequiredBreakSchedule(workTime time.Duration, policy Policy) []BreakRequirement {
+	var schedule []BreakRequirement
+	if policy.DistributedBreak.WorkTime > 0 {
+		for after := policy.DistributedBreak.WorkTime; after < workTime; after += policy.DistributedBreak.WorkTime {
+			schedule = append(schedule, BreakRequirement{After: after, Amount: policy.DistributedBreak.BreakTime})
+		}
+		return schedule
+	}
+
+	var breakTime time.Duration
+	for _, rule := range policy.BreakSchedule() {
+		if rule.WorkTime >= workTime {
+			break
+		}
+		schedule = append(schedule, BreakRequirement{After: rule.WorkTime, Amount: rule.BreakTime - breakTime})
+		breakTime = rule.BreakTime
+	}
+	return schedule
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredBreak(t *testing.T) {
+	assert.Equal(t, dur(0, 0), DefaultPolicy.RequiredBreak(dur(6, 0)))
+	assert.Equal(t, dur(0, 30), DefaultPolicy.RequiredBreak(dur(6, 1)))
+	assert.Equal(t, dur(0, 30), DefaultPolicy.RequiredBreak(dur(9, 0)))
+	assert.Equal(t, dur(0, 45), DefaultPolicy.RequiredBreak(dur(9, 1)))
+}
+
+func TestMarginalBreakCost(t *testing.T) {
+	assert.Equal(t, dur(0, 15), MarginalBreakCost(dur(8, 30), dur(0, 30), dur(1, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), MarginalBreakCost(dur(8, 30), dur(0, 45), dur(1, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), MarginalBreakCost(dur(7, 0), dur(0, 30), dur(1, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 45), MarginalBreakCost(dur(5, 0), dur(0, 0), dur(5, 0), DefaultPolicy))
+}
+
+func TestRequiredPresence(t *testing.T) {
+	assert.Equal(t, dur(8, 30), RequiredPresence(dur(8, 0), dur(0, 20), DefaultPolicy))
+	assert.Equal(t, dur(8, 50), RequiredPresence(dur(8, 0), dur(0, 50), DefaultPolicy))
+	assert.Equal(t, dur(6, 0), RequiredPresence(dur(6, 0), dur(0, 0), DefaultPolicy))
+	assert.Equal(t, dur(10, 45), RequiredPresence(dur(10, 0), dur(0, 30), DefaultPolicy))
+}
+
+func TestScheduledVsMandatedBreak(t *testing.T) {
+	assert.Equal(t, -dur(0, 15), ScheduledVsMandatedBreak(dur(0, 30), dur(9, 30), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), ScheduledVsMandatedBreak(dur(0, 30), dur(9, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 15), ScheduledVsMandatedBreak(dur(0, 45), dur(8, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 30), ScheduledVsMandatedBreak(dur(0, 30), dur(5, 0), DefaultPolicy))
+}
+
+func TestBreakSchedule(t *testing.T) {
+	assert.Equal(t, []BreakRule{
+		{WorkTime: dur(6, 0), BreakTime: dur(0, 30)},
+		{WorkTime: dur(9, 0), BreakTime: dur(0, 45)},
+	}, DefaultPolicy.BreakSchedule())
+
+	policy := Policy{BreakRules: []BreakRule{
+		{WorkTime: dur(9, 0), BreakTime: dur(0, 45)},
+		{WorkTime: dur(8, 0), BreakTime: dur(0, 15)},
+		{WorkTime: dur(6, 0), BreakTime: dur(0, 30)},
+	}}
+	assert.Equal(t, []BreakRule{
+		{WorkTime: dur(6, 0), BreakTime: dur(0, 30)},
+		{WorkTime: dur(9, 0), BreakTime: dur(0, 45)},
+	}, policy.BreakSchedule())
+}
+
+func TestMinBreakForWork(t *testing.T) {
+	assert.Equal(t, dur(0, 45), MinBreakForWork(dur(9, 30), DefaultPolicy))
+	assert.Equal(t, dur(0, 30), MinBreakForWork(dur(9, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 30), MinBreakForWork(dur(7, 0), DefaultPolicy))
+	assert.Equal(t, dur(0, 0), MinBreakForWork(dur(6, 0), DefaultPolicy))
+}
+
+func TestRequiredBreakRatio(t *testing.T) {
+	assert.Equal(t, 0.0, RequiredBreakRatio(dur(0, 0), DefaultPolicy))
+	assert.Equal(t, 0.0, RequiredBreakRatio(dur(6, 0), DefaultPolicy))
+	assert.InDelta(t, 30.0/361.0, RequiredBreakRatio(dur(6, 1), DefaultPolicy), 1e-9)
+	assert.InDelta(t, 30.0/540.0, RequiredBreakRatio(dur(9, 0), DefaultPolicy), 1e-9)
+	assert.InDelta(t, 45.0/541.0, RequiredBreakRatio(dur(9, 1), DefaultPolicy), 1e-9)
+}
+
+func TestBreakBasedOnPresence(t *testing.T) {
+	policy := DefaultPolicy
+	policy.BreakBasedOnPresence = true
+
+	// 9:30 presence with 40 minutes of break
+	workTime, breakTime := DefaultPolicy.AccountedWorkTime(dur(8, 50), dur(0, 40))
+	assert.Equal(t, dur(8, 50), workTime)
+	assert.Equal(t, dur(0, 40), breakTime)
+	assert.Equal(t, dur(0, 30), DefaultPolicy.RequiredBreakFor(dur(8, 50), dur(0, 40)))
+
+	workTime, breakTime = policy.AccountedWorkTime(dur(8, 50), dur(0, 40))
+	assert.Equal(t, dur(8, 45), workTime)
+	assert.Equal(t, dur(0, 45), breakTime)
+	assert.Equal(t, dur(0, 45), policy.RequiredBreakFor(dur(8, 50), dur(0, 40)))
+
+	workTime, breakTime = policy.AccountedWorkTime(dur(6, 5), dur(0, 0))
+	assert.Equal(t, dur(5, 35), workTime)
+	assert.Equal(t, dur(0, 30), breakTime)
+}
+
+func TestRequiredBreakFunc(t *testing.T) {
+	requiredBreak := DefaultPolicy.RequiredBreakFunc()
+	for _, workTime := range []time.Duration{dur(0, 0), dur(5, 59), dur(6, 0), dur(6, 1), dur(9, 0), dur(9, 1), dur(12, 0)} {
+		assert.Equal(t, DefaultPolicy.RequiredBreak(workTime), requiredBreak(workTime))
+	}
+	assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() { requiredBreak(dur(7, 0)) }))
+}
+
+func TestSlidingBreakAtThreshold(t *testing.T) {
+	workTime, breakTime := DefaultPolicy.AccountedWorkTime(dur(6, 5), dur(0, 0))
+	assert.Equal(t, dur(6, 0), workTime)
+	assert.Equal(t, dur(0, 5), breakTime)
+
+	policy := DefaultPolicy
+	policy.SlidingBreakAtThreshold = false
+	workTime, breakTime = policy.AccountedWorkTime(dur(6, 5), dur(0, 0))
+	assert.Equal(t, dur(5, 35), workTime)
+	assert.Equal(t, dur(0, 30), breakTime)
+
+	workTime, breakTime = policy.AccountedWorkTime(dur(8, 44), dur(0, 0))
+	assert.Equal(t, dur(8, 14), workTime)
+	assert.Equal(t, dur(0, 30), breakTime)
+}
+
+func TestRuleOrder(t *testing.T) {
+	policy := DefaultPolicy
+	policy.SlidingBreakAtThreshold = false
+
+	// the default order applies the 6h rule first, so 09:01 of work drops below the 9h threshold
+	workTime, breakTime := policy.AccountedWorkTime(dur(9, 1), dur(0, 0))
+	assert.Equal(t, dur(8, 31), workTime)
+	assert.Equal(t, dur(0, 30), breakTime)
+
+	policy.RuleOrder = []RuleKind{0, 1, RuleMaxWorkTime}
+	workTime, breakTime = policy.AccountedWorkTime(dur(9, 1), dur(0, 0))
+	assert.Equal(t, dur(8, 31), workTime)
+	assert.Equal(t, dur(0, 30), breakTime)
+
+	policy.RuleOrder = []RuleKind{1, 0, RuleMaxWorkTime}
+	workTime, breakTime = policy.AccountedWorkTime(dur(9, 1), dur(0, 0))
+	assert.Equal(t, dur(8, 16), workTime)
+	assert.Equal(t, dur(0, 45), breakTime)
+
+	// capping first does not change the result
+	for _, order := range [][]RuleKind{nil, {RuleMaxWorkTime, 0, 1}} {
+		policy.RuleOrder = order
+		workTime, breakTime = policy.AccountedWorkTime(dur(11, 0), dur(0, 10))
+		assert.Equal(t, dur(10, 0), workTime)
+		assert.Equal(t, dur(1, 10), breakTime)
+	}
+
+	policy.RuleOrder = []RuleKind{}
+	workTime, breakTime = policy.AccountedWorkTime(dur(11, 0), dur(0, 0))
+	assert.Equal(t, dur(11, 0), workTime)
+	assert.Equal(t, dur(0, 0), breakTime)
+}
+
+func TestComparePolicies(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 20)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	policy := DefaultPolicy
+	policy.BreakRules = []BreakRule{{WorkTime: dur(6, 0), BreakTime: dur(0, 15)}, {WorkTime: dur(9, 0), BreakTime: dur(0, 45)}}
+
+	resultA, resultB, delta, err := ComparePolicies(entries, DefaultPolicy, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), resultA.AccountedWorkTime)
+	assert.Equal(t, dur(8, 10), resultB.AccountedWorkTime)
+	assert.Equal(t, dur(0, 10), delta)
+}
+
+func TestRequiredBreakSchedule(t *testing.T) {
+	assert.Equal(t, []BreakRequirement{
+		{After: dur(6, 0), Amount: dur(0, 30)},
+		{After: dur(9, 0), Amount: dur(0, 15)},
+	}, RequiredBreakSchedule(dur(9, 30), DefaultPolicy))
+	assert.Empty(t, RequiredBreakSchedule(dur(6, 0), DefaultPolicy))
+
+	policy := DefaultPolicy
+	policy.DistributedBreak = BreakRule{WorkTime: dur(4, 0), BreakTime: dur(0, 15)}
+	assert.Equal(t, []BreakRequirement{
+		{After: dur(4, 0), Amount: dur(0, 15)},
+		{After: dur(8, 0), Amount: dur(0, 15)},
+	}, RequiredBreakSchedule(dur(9, 0), policy))
+}
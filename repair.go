@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+)
+
+var (
+	// ErrNothingToRepair is returned when no repair suggestion is applicable to a list of entries.
+	ErrNothingToRepair = fmt.Errorf("nothing to repair")
+)
+
+// SuggestTripReturn returns a come entry and the insert index to close a trip that was directly followed by a leave.
+//
+// The suggested return is placed at the time of the leave, so the trip lasts until the end of the day.
+func SuggestTripReturn(entries []Entry) (Entry, int, error) {
+	for i := 0; i+1 < len(entries); i++ {
+		if entries[i].Type == EntryTypeTrip && entries[i+1].Type == EntryTypeLeave {
+			return Entry{Type: EntryTypeCome, Time: entries[i+1].Time}, i + 1, nil
+		}
+	}
+	return Entry{}, 0, ErrNothingToRepair
+}
+
+// RepairKind denotes whether a repair inserts or removes an entry.
+type RepairKind string
+
+const (
+	// RepairInsert denotes a repair that inserts a new entry.
+	RepairInsert RepairKind = "insert"
+	// RepairRemove denotes a repair that removes an existing entry.
+	RepairRemove RepairKind = "remove"
+)
+
+// Repair describes a single change to make a list of entries valid.
+type Repair struct {
+	Kind RepairKind
+	// Index refers to the original entries. Inserted entries are placed before the entry at Index.
+	Index int
+	// Entry is the inserted or removed entry.
+	Entry  Entry
+	Reason string
+}
+
+// SuggestRepairs returns the changes needed to make an invalid sequence of entries valid.
+//
+// Missing come entries are inserted at the time of the previous leave, or at the start of business hours
+// for the first entry. Duplicate come and trip entries are removed. Repairs are sorted by index and should
+// be applied in reverse order to keep the indices valid.
+func SuggestRepairs(entries []Entry, policy Policy) ([]Repair, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	var repairs []Repair
+	insertCome := func(i int, reason string) {
+		comeTime := entries[i].Time
+		if i > 0 {
+			comeTime = entries[i-1].Time
+		} else if businessStart := policy.BusinessHours(comeTime).Start; businessStart.Before(comeTime) {
+			comeTime = businessStart
+		}
+		repairs = append(repairs, Repair{Kind: RepairInsert, Index: i, Entry: Entry{Type: EntryTypeCome, Time: comeTime}, Reason: reason})
+	}
+
+	state := StateNone
+	for i, entry := range entries {
+		switch state {
+		case StateNone:
+			if entry.Type == EntryTypeLeave {
+				insertCome(i, fmt.Sprintf("leave at index %d without come", i))
+			} else if entry.Type == EntryTypeTrip {
+				insertCome(i, fmt.Sprintf("%s at index %d", ErrTripOutsideWork.Error(), i))
+			} else if entry.Type == EntryTypeOnCall {
+				insertCome(i, fmt.Sprintf("on-call at index %d without come", i))
+			}
+		case StateWorking:
+			if entry.Type == EntryTypeCome {
+				repairs = append(repairs, Repair{Kind: RepairRemove, Index: i, Entry: entry, Reason: fmt.Sprintf("duplicate come at index %d", i)})
+				continue
+			}
+		case StateTrip, StateOnCall:
+			if entry.Type == EntryTypeLeave || (entry.Type != EntryTypeCome && entryState(entry.Type) != state) {
+				repairs = append(repairs, Repair{Kind: RepairInsert, Index: i, Entry: Entry{Type: EntryTypeCome, Time: entry.Time}, Reason: fmt.Sprintf("%s at index %d during %s", entry.Type, i, state)})
+			} else if entry.Type != EntryTypeCome {
+				repairs = append(repairs, Repair{Kind: RepairRemove, Index: i, Entry: entry, Reason: fmt.Sprintf("duplicate %s at index %d", entry.Type, i)})
+				continue
+			}
+		}
+		state = entryState(entry.Type)
+	}
+
+	if len(repairs) == 0 {
+		return nil, ErrNothingToRepair
+	}
+	return repairs, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestTripReturn(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(13, 0)},
+		{Type: EntryTypeLeave, Time: tim(16, 0)},
+	}
+
+	entry, index, err := SuggestTripReturn(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, Entry{Type: EntryTypeCome, Time: tim(16, 0)}, entry)
+	assert.Equal(t, 2, index)
+
+	_, _, _, err = ComputeWorkTime(slices.Insert(entries, index, entry))
+	assert.NoError(t, err)
+
+	_, _, err = SuggestTripReturn(entries[:1])
+	assert.ErrorIs(t, err, ErrNothingToRepair)
+}
+
+func TestSuggestRepairs(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		repairs []Repair
+	}{
+		{
+			name: "leave first",
+			entries: []Entry{
+				{Type: EntryTypeLeave, Time: tim(12, 0)},
+				{Type: EntryTypeCome, Time: tim(12, 30)},
+				{Type: EntryTypeLeave, Time: tim(17, 0)},
+			},
+			repairs: []Repair{
+				{Kind: RepairInsert, Index: 0, Entry: Entry{Type: EntryTypeCome, Time: tim(6, 30)}, Reason: "leave at index 0 without come"},
+			},
+		},
+		{
+			name: "missing come between leaves",
+			entries: []Entry{
+				{Type: EntryTypeCome, Time: tim(8, 0)},
+				{Type: EntryTypeLeave, Time: tim(12, 0)},
+				{Type: EntryTypeLeave, Time: tim(17, 0)},
+			},
+			repairs: []Repair{
+				{Kind: RepairInsert, Index: 2, Entry: Entry{Type: EntryTypeCome, Time: tim(12, 0)}, Reason: "leave at index 2 without come"},
+			},
+		},
+		{
+			name: "duplicates and unfinished trip",
+			entries: []Entry{
+				{Type: EntryTypeCome, Time: tim(8, 0)},
+				{Type: EntryTypeCome, Time: tim(8, 1)},
+				{Type: EntryTypeTrip, Time: tim(13, 0)},
+				{Type: EntryTypeTrip, Time: tim(13, 2)},
+				{Type: EntryTypeLeave, Time: tim(16, 0)},
+			},
+			repairs: []Repair{
+				{Kind: RepairRemove, Index: 1, Entry: Entry{Type: EntryTypeCome, Time: tim(8, 1)}, Reason: "duplicate come at index 1"},
+				{Kind: RepairRemove, Index: 3, Entry: Entry{Type: EntryTypeTrip, Time: tim(13, 2)}, Reason: "duplicate trip at index 3"},
+				{Kind: RepairInsert, Index: 4, Entry: Entry{Type: EntryTypeCome, Time: tim(16, 0)}, Reason: "leave at index 4 during trip"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repairs, err := SuggestRepairs(test.entries, DefaultPolicy)
+			assert.NoError(t, err)
+			assert.Equal(t, test.repairs, repairs)
+
+			repaired := slices.Clone(test.entries)
+			for i := len(repairs) - 1; i >= 0; i-- {
+				if repairs[i].Kind == RepairInsert {
+					repaired = slices.Insert(repaired, repairs[i].Index, repairs[i].Entry)
+				} else {
+					repaired = slices.Delete(repaired, repairs[i].Index, repairs[i].Index+1)
+				}
+			}
+			_, err = ComputeResult(repaired, tim(18, 0), DefaultPolicy)
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err := SuggestRepairs([]Entry{{Type: EntryTypeCome, Time: tim(8, 0)}, {Type: EntryTypeLeave, Time: tim(16, 0)}}, DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNothingToRepair)
+	_, err = SuggestRepairs(nil, DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// UnpaidTime returns the part of the presence time that is not accounted as work.
+//
+// This is the taken break plus any additional break enforced by the policy. The result must be computed with policy.
+func UnpaidTime(result WorkTimeResult, policy Policy) time.Duration {
+	return max(0, result.Presence()-result.AccountedWorkTime)
+}
+
+// AverageTimes returns the mean time of day of start and leave over all days.
+//
+// The returned times are located on January 1 of year 1 in UTC. For open days, the live end of the result is used.
+func AverageTimes(days []WorkTimeResult) (avgStart, avgLeave time.Time) {
+	if len(days) == 0 {
+		return time.Time{}, time.Time{}
+	}
+
+	var sumStart, sumLeave time.Duration
+	for _, day := range days {
+		sumStart += timeOfDay(day.Start)
+		sumLeave += timeOfDay(day.Start.Add(day.Presence()))
+	}
+	return time.Time{}.Add(sumStart / time.Duration(len(days))), time.Time{}.Add(sumLeave / time.Duration(len(days)))
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return t.Sub(midnight(t))
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// VoluntaryBreak returns the part of the taken break exceeding the mandated break.
+func VoluntaryBreak(result WorkTimeResult, policy Policy) time.Duration {
+	return max(0, result.BreakTime-policy.RequiredBreakFor(result.WorkTime, result.BreakTime))
+}
+
+// ExcessBreakCausingShortfall returns the part of the voluntary break that caused missing the target work time.
+//
+// It is zero if the target is met.
+func ExcessBreakCausingShortfall(result WorkTimeResult, target time.Duration, policy Policy) time.Duration {
+	return min(VoluntaryBreak(result, policy), max(0, target-result.AccountedWorkTime))
+}
+
+// ComputeBoth returns the result with exact precision and with all times and durations truncated to minutes.
+func ComputeBoth(entries []Entry, policy Policy) (exact, truncated WorkTimeResult, err error) {
+	exact, err = ComputeResult(entries, time.Now(), policy)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, err
+	}
+
+	truncated = exact
+	truncated.Start = exact.Start.Truncate(time.Minute)
+	truncated.End = exact.End.Truncate(time.Minute)
+	truncated.WorkTime = noSeconds(exact.WorkTime)
+	truncated.BreakTime = noSeconds(exact.BreakTime)
+	truncated.TripTime = noSeconds(exact.TripTime)
+	truncated.OnCallTime = noSeconds(exact.OnCallTime)
+	truncated.UncreditedOnCallTime = noSeconds(exact.UncreditedOnCallTime)
+	truncated.TripCredit = noSeconds(exact.TripCredit)
+	truncated.LongestBreak = noSeconds(exact.LongestBreak)
+	truncated.AccountedWorkTime = noSeconds(exact.AccountedWorkTime)
+	truncated.AccountedBreakTime = noSeconds(exact.AccountedBreakTime)
+	truncated.ExcessBreakTime = noSeconds(exact.ExcessBreakTime)
+	truncated.ProjectedWork = noSeconds(exact.ProjectedWork)
+	return exact, truncated, nil
+}
+
+// SumWorkTime returns the exact sums of accounted work time, accounted break time and presence over all results.
+func SumWorkTime(results []WorkTimeResult) (totalWork, totalBreak, totalPresence time.Duration) {
+	for _, result := range results {
+		totalWork += result.AccountedWorkTime
+		totalBreak += result.AccountedBreakTime
+		totalPresence += result.Presence()
+	}
+	return totalWork, totalBreak, totalPresence
+}
+
+// FlagOutlierStart returns true if the start time of day deviates from the historical average start by more than threshold.
+//
+// This helps to spot typos like 19:00 entered instead of 09:00.
+func FlagOutlierStart(result WorkTimeResult, historicalAvg time.Time, threshold time.Duration) bool {
+	deviation := timeOfDay(result.Start) - timeOfDay(historicalAvg)
+	if deviation < 0 {
+		deviation = -deviation
+	}
+	return deviation > threshold
+}
+
+// TotalOnPremises returns the time between first come and last leave without trips and on-call time. Open days end at now.
+//
+// In contrast to the work time, breaks are included as they are assumed to be taken on the premises.
+func TotalOnPremises(entries []Entry, now time.Time) (time.Duration, error) {
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return 0, err
+	}
+	return times.End.Sub(times.Start) - times.TripTime - times.OnCallTime, nil
+}
+
+// TotalSpan returns the time between the first and the last entry including breaks and trips.
+//
+// In contrast to the day computations, entries may span multiple days.
+func TotalSpan(entries []Entry) (time.Duration, error) {
+	if len(entries) == 0 {
+		return 0, ErrNoEntries
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.Before(entries[i-1].Time) {
+			return 0, fmt.Errorf("%w: %s entry at index %d", ErrUnsortedEntries, entries[i].Type, i)
+		}
+	}
+	return entries[len(entries)-1].Time.Sub(entries[0].Time), nil
+}
+
+// LiveVsFinal returns the live result as it was computed at now before the final leave was recorded, the final result
+// and the difference of accounted work time from live to final.
+//
+// The last entry must be a leave, otherwise ErrOpenDay is returned.
+func LiveVsFinal(entries []Entry, now time.Time, policy Policy) (live, final WorkTimeResult, delta time.Duration, err error) {
+	if len(entries) == 0 {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, ErrNoEntries
+	}
+	if entries[len(entries)-1].Type != EntryTypeLeave {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, ErrOpenDay
+	}
+
+	final, err = ComputeResult(entries, now, policy)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, err
+	}
+	live, err = ComputeResult(entries[:len(entries)-1], now, policy)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, 0, err
+	}
+	return live, final, final.AccountedWorkTime - live.AccountedWorkTime, nil
+}
+
+// ResultsEqual returns whether both lists of entries result in accounted work and break times that differ by at most tolerance.
+//
+// The computed results are returned for debugging.
+func ResultsEqual(a, b []Entry, tolerance time.Duration, policy Policy) (equal bool, resultA, resultB WorkTimeResult, err error) {
+	now := time.Now()
+	resultA, err = ComputeResult(a, now, policy)
+	if err != nil {
+		return false, WorkTimeResult{}, WorkTimeResult{}, err
+	}
+	resultB, err = ComputeResult(b, now, policy)
+	if err != nil {
+		return false, WorkTimeResult{}, WorkTimeResult{}, err
+	}
+
+	within := func(x, y time.Duration) bool {
+		return max(x-y, y-x) <= tolerance
+	}
+	equal = within(resultA.AccountedWorkTime, resultB.AccountedWorkTime) && within(resultA.AccountedBreakTime, resultB.AccountedBreakTime)
+	return equal, resultA, resultB, nil
+}
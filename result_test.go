@@ -0,0 +1,217 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnpaidTime(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 15)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 15)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	// 30 minutes of break taken as mandated
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 30), UnpaidTime(result, DefaultPolicy))
+
+	// only 15 minutes of contiguous break count, so another 15 minutes are deducted
+	policy := DefaultPolicy
+	policy.RequireContiguousBreak = true
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 45), UnpaidTime(result, policy))
+
+	// credited minimum shift beyond the presence
+	policy = DefaultPolicy
+	policy.MinShiftLength = dur(3, 0)
+	result, err = ComputeResult(entries[:2], tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Zero(t, UnpaidTime(result, policy))
+}
+
+func TestAverageTimes(t *testing.T) {
+	days := []WorkTimeResult{
+		{Start: tim(8, 0), End: tim(16, 30)},
+		{Start: tim(9, 0).AddDate(0, 0, 1), End: tim(18, 0).AddDate(0, 0, 1)},
+	}
+
+	avgStart, avgLeave := AverageTimes(days)
+	assert.Equal(t, "08:30", avgStart.Format("15:04"))
+	assert.Equal(t, "17:15", avgLeave.Format("15:04"))
+	assert.Equal(t, 1, avgStart.Year())
+}
+
+func TestVoluntaryBreak(t *testing.T) {
+	assert.Equal(t, dur(0, 30), VoluntaryBreak(WorkTimeResult{WorkTime: dur(8, 0), BreakTime: dur(1, 0)}, DefaultPolicy))
+	assert.Equal(t, dur(0, 0), VoluntaryBreak(WorkTimeResult{WorkTime: dur(8, 0), BreakTime: dur(0, 15)}, DefaultPolicy))
+	assert.Equal(t, dur(0, 15), VoluntaryBreak(WorkTimeResult{WorkTime: dur(5, 0), BreakTime: dur(0, 15)}, DefaultPolicy))
+}
+
+func TestExcessBreakCausingShortfall(t *testing.T) {
+	result := WorkTimeResult{WorkTime: dur(7, 40), BreakTime: dur(1, 10), AccountedWorkTime: dur(7, 40)}
+	assert.Equal(t, dur(0, 20), ExcessBreakCausingShortfall(result, dur(8, 0), DefaultPolicy))
+
+	result = WorkTimeResult{WorkTime: dur(7, 0), BreakTime: dur(0, 40), AccountedWorkTime: dur(7, 0)}
+	assert.Equal(t, dur(0, 10), ExcessBreakCausingShortfall(result, dur(8, 0), DefaultPolicy))
+
+	result = WorkTimeResult{WorkTime: dur(8, 10), BreakTime: dur(1, 10), AccountedWorkTime: dur(8, 10)}
+	assert.Equal(t, dur(0, 0), ExcessBreakCausingShortfall(result, dur(8, 0), DefaultPolicy))
+}
+
+func TestComputeBoth(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0).Add(20 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 45).Add(50 * time.Second)},
+	}
+
+	exact, truncated, err := ComputeBoth(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 15)+30*time.Second, exact.AccountedWorkTime)
+	assert.Equal(t, dur(8, 15), truncated.AccountedWorkTime)
+	assert.Equal(t, tim(8, 0), truncated.Start)
+	assert.Equal(t, tim(16, 45), truncated.End)
+	assert.Less(t, exact.AccountedWorkTime-truncated.AccountedWorkTime, time.Minute)
+	assert.Less(t, exact.WorkTime-truncated.WorkTime, time.Minute)
+}
+
+func TestSumWorkTime(t *testing.T) {
+	results := []WorkTimeResult{
+		{Start: tim(8, 0), End: tim(16, 30), AccountedWorkTime: dur(8, 0), AccountedBreakTime: dur(0, 30)},
+		{Start: tim(9, 0), End: tim(18, 45), AccountedWorkTime: dur(9, 0), AccountedBreakTime: dur(0, 45)},
+		{Start: tim(7, 30), End: tim(13, 0).Add(20 * time.Second), AccountedWorkTime: dur(5, 30) + 20*time.Second},
+	}
+
+	totalWork, totalBreak, totalPresence := SumWorkTime(results)
+	assert.Equal(t, dur(22, 30)+20*time.Second, totalWork)
+	assert.Equal(t, dur(1, 15), totalBreak)
+	assert.Equal(t, dur(23, 45)+20*time.Second, totalPresence)
+
+	totalWork, totalBreak, totalPresence = SumWorkTime(nil)
+	assert.Zero(t, totalWork)
+	assert.Zero(t, totalBreak)
+	assert.Zero(t, totalPresence)
+}
+
+func TestFlagOutlierStart(t *testing.T) {
+	avgStart := time.Time{}.Add(dur(9, 0))
+	assert.True(t, FlagOutlierStart(WorkTimeResult{Start: tim(19, 0)}, avgStart, dur(3, 0)))
+	assert.True(t, FlagOutlierStart(WorkTimeResult{Start: tim(5, 30)}, avgStart, dur(3, 0)))
+	assert.False(t, FlagOutlierStart(WorkTimeResult{Start: tim(11, 0)}, avgStart, dur(3, 0)))
+	assert.False(t, FlagOutlierStart(WorkTimeResult{Start: tim(12, 0)}, avgStart, dur(3, 0)))
+}
+
+func TestTotalOnPremises(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeTrip, Time: tim(14, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	onPremises, err := TotalOnPremises(entries, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 30), onPremises)
+
+	onPremises, err = TotalOnPremises(entries[:3], tim(13, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 0), onPremises)
+
+	_, err = TotalOnPremises(nil, tim(13, 0))
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestTotalSpan(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(22, 0)},
+		{Type: EntryTypeLeave, Time: tim(23, 30)},
+		{Type: EntryTypeCome, Time: tim(23, 45)},
+		{Type: EntryTypeLeave, Time: tim(6, 0).AddDate(0, 0, 1)},
+	}
+
+	span, err := TotalSpan(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), span)
+
+	span, err = TotalSpan(entries[:1])
+	assert.NoError(t, err)
+	assert.Zero(t, span)
+
+	_, err = TotalSpan([]Entry{entries[1], entries[0]})
+	assert.ErrorIs(t, err, ErrUnsortedEntries)
+
+	_, err = TotalSpan(nil)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestLiveVsFinal(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	live, final, delta, err := LiveVsFinal(entries, tim(16, 40), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 10), live.AccountedWorkTime)
+	assert.Equal(t, tim(16, 40), live.End)
+	assert.Equal(t, dur(8, 0), final.AccountedWorkTime)
+	assert.Equal(t, tim(16, 30), final.End)
+	assert.Equal(t, -dur(0, 10), delta)
+
+	_, _, _, err = LiveVsFinal(entries[:3], tim(16, 40), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrOpenDay)
+	_, _, _, err = LiveVsFinal(nil, tim(16, 40), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestResultsEqual(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	normalized := append(slices.Clone(entries), entries[1], entries[2])
+	rand.New(rand.NewSource(1)).Shuffle(len(normalized), func(i, j int) {
+		normalized[i], normalized[j] = normalized[j], normalized[i]
+	})
+	slices.SortFunc(normalized, func(a, b Entry) int {
+		return a.Time.Compare(b.Time)
+	})
+	normalized = slices.Compact(normalized)
+
+	equal, resultA, resultB, err := ResultsEqual(entries, normalized, 0, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, equal)
+	assert.Equal(t, resultA, resultB)
+
+	shifted := slices.Clone(entries)
+	shifted[3].Time = tim(16, 31)
+	equal, _, resultB, err = ResultsEqual(entries, shifted, 0, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.False(t, equal)
+	assert.Equal(t, dur(8, 1), resultB.AccountedWorkTime)
+
+	equal, _, _, err = ResultsEqual(entries, shifted, time.Minute, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, equal)
+
+	_, _, _, err = ResultsEqual(entries, nil, 0, DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
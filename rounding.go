@@ -0,0 +1,129 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// RoundNearest rounds to the nearest multiple of the granularity.
+	RoundNearest RoundMode = iota
+	// RoundDown rounds to the previous multiple of the granularity.
+	RoundDown
+	// RoundUp rounds to the next multiple of the granularity.
+	RoundUp
+)
+
+// RoundMode denotes the direction of rounding.
+type RoundMode int
+
+// RoundConfig describes how entry times are rounded.
+type RoundConfig struct {
+	Granularity time.Duration
+	Mode        RoundMode
+}
+
+// Round returns t rounded to the configured granularity. A granularity of zero leaves t untouched.
+func (c RoundConfig) Round(t time.Time) time.Time {
+	if c.Granularity <= 0 {
+		return t
+	}
+
+	switch c.Mode {
+	case RoundDown:
+		return t.Truncate(c.Granularity)
+	case RoundUp:
+		truncated := t.Truncate(c.Granularity)
+		if truncated.Equal(t) {
+			return t
+		}
+		return truncated.Add(c.Granularity)
+	default:
+		return t.Round(c.Granularity)
+	}
+}
+
+// RoundDuration returns d rounded to the configured granularity. A granularity of zero leaves d untouched.
+func (c RoundConfig) RoundDuration(d time.Duration) time.Duration {
+	return c.Round(time.Time{}.Add(d)).Sub(time.Time{})
+}
+
+// roundEntries returns a copy of entries with times rounded according to the rounding config of their source.
+func roundEntries(entries []Entry, policy Policy) []Entry {
+	rounded := make([]Entry, len(entries))
+	for i, entry := range entries {
+		if config, ok := policy.SourceRounding[entry.Source]; ok {
+			entry.Time = config.Round(entry.Time)
+		}
+		rounded[i] = entry
+	}
+	return rounded
+}
+
+// roundPresence returns a copy of entries with the first and last entry rounded to full minutes according to
+// RoundPresenceToMinute of the policy. The end of open days at now is rounded likewise.
+func roundPresence(entries []Entry, now time.Time, policy Policy) ([]Entry, time.Time) {
+	if policy.RoundPresenceToMinute == nil || len(entries) == 0 {
+		return entries, now
+	}
+
+	startRounding := RoundConfig{Granularity: time.Minute, Mode: *policy.RoundPresenceToMinute}
+	endRounding := startRounding
+	switch startRounding.Mode {
+	case RoundDown:
+		startRounding.Mode = RoundUp
+	case RoundUp:
+		startRounding.Mode = RoundDown
+	}
+
+	rounded := slices.Clone(entries)
+	rounded[0].Time = startRounding.Round(rounded[0].Time)
+	if last := len(rounded) - 1; last > 0 && rounded[last].Type == EntryTypeLeave {
+		rounded[last].Time = endRounding.Round(rounded[last].Time)
+	}
+	return rounded, endRounding.Round(now)
+}
+
+// snapEntries returns a copy of entries with come entries near the shift start of the policy moved to the shift start.
+func snapEntries(entries []Entry, policy Policy) []Entry {
+	snapped := make([]Entry, len(entries))
+	for i, entry := range entries {
+		if policy.SnapWindow > 0 && entry.Type == EntryTypeCome {
+			shiftStart := midnight(entry.Time).Add(policy.ShiftStart)
+			if offset := entry.Time.Sub(shiftStart); offset >= -policy.SnapWindow && offset <= policy.SnapWindow {
+				entry.Time = shiftStart
+			}
+		}
+		snapped[i] = entry
+	}
+	return snapped
+}
+
+// RoundingImpact returns how much the accounted work time changes when rounding all entries to granularity to instead of from.
+//
+// The rounding mode of each source is taken from the policy. Sources without rounding config are rounded to the nearest value.
+func RoundingImpact(entries []Entry, from, to time.Duration, policy Policy) (time.Duration, error) {
+	now := time.Now()
+	withGranularity := func(granularity time.Duration) (time.Duration, error) {
+		rounded := policy
+		rounded.SourceRounding = make(map[string]RoundConfig)
+		for _, entry := range entries {
+			rounded.SourceRounding[entry.Source] = RoundConfig{Granularity: granularity, Mode: policy.SourceRounding[entry.Source].Mode}
+		}
+		result, err := ComputeResult(entries, now, rounded)
+		if err != nil {
+			return 0, err
+		}
+		return result.AccountedWorkTime, nil
+	}
+
+	fromWorkTime, err := withGranularity(from)
+	if err != nil {
+		return 0, err
+	}
+	toWorkTime, err := withGranularity(to)
+	if err != nil {
+		return 0, err
+	}
+	return toWorkTime - fromWorkTime, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundConfig(t *testing.T) {
+	entryTime := tim(16, 32).Add(10 * time.Second)
+	assert.Equal(t, tim(16, 30), RoundConfig{Granularity: 5 * time.Minute, Mode: RoundNearest}.Round(entryTime))
+	assert.Equal(t, tim(16, 30), RoundConfig{Granularity: 5 * time.Minute, Mode: RoundDown}.Round(entryTime))
+	assert.Equal(t, tim(16, 35), RoundConfig{Granularity: 5 * time.Minute, Mode: RoundUp}.Round(entryTime))
+	assert.Equal(t, tim(16, 35), RoundConfig{Granularity: 5 * time.Minute, Mode: RoundUp}.Round(tim(16, 35)))
+	assert.Equal(t, entryTime, RoundConfig{}.Round(entryTime))
+}
+
+func TestComputeResultSourceRounding(t *testing.T) {
+	policy := DefaultPolicy
+	policy.SourceRounding = map[string]RoundConfig{
+		"web": {Granularity: 5 * time.Minute, Mode: RoundNearest},
+	}
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 3).Add(27 * time.Second), Source: "terminal"},
+		{Type: EntryTypeLeave, Time: tim(12, 32), Source: "web"},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 3).Add(27*time.Second), result.Start)
+	assert.Equal(t, tim(12, 30), result.End)
+	assert.Equal(t, dur(4, 26)+33*time.Second, result.WorkTime)
+}
+
+func TestRoundDuration(t *testing.T) {
+	assert.Equal(t, dur(0, 30), RoundConfig{Granularity: 15 * time.Minute, Mode: RoundUp}.RoundDuration(dur(0, 22)))
+	assert.Equal(t, dur(0, 15), RoundConfig{Granularity: 15 * time.Minute, Mode: RoundDown}.RoundDuration(dur(0, 22)))
+	assert.Equal(t, dur(0, 15), RoundConfig{Granularity: 15 * time.Minute, Mode: RoundNearest}.RoundDuration(dur(0, 22)))
+	assert.Equal(t, dur(0, 22), RoundConfig{}.RoundDuration(dur(0, 22)))
+}
+
+func TestComputeResultTripRounding(t *testing.T) {
+	policy := DefaultPolicy
+	policy.SourceRounding = map[string]RoundConfig{
+		"terminal": {Granularity: 5 * time.Minute, Mode: RoundNearest},
+	}
+	policy.TripRoundGranularity = 15 * time.Minute
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 2), Source: "terminal"},
+		{Type: EntryTypeTrip, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 22)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 45)},
+		{Type: EntryTypeLeave, Time: tim(16, 58), Source: "terminal"},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 0), result.Start)
+	assert.Equal(t, tim(17, 0), result.End)
+	assert.Equal(t, dur(0, 22), result.TripTime)
+	assert.Equal(t, dur(0, 8), result.TripCredit)
+	assert.Equal(t, dur(8, 15), result.WorkTime)
+	assert.Equal(t, dur(0, 45), result.BreakTime)
+	assert.Equal(t, dur(8, 23), result.AccountedWorkTime)
+	assert.Equal(t, dur(0, 45), result.AccountedBreakTime)
+
+	policy.TripRoundGranularity = 0
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 22), result.TripTime)
+	assert.Equal(t, dur(8, 15), result.AccountedWorkTime)
+}
+
+func TestTripRoundingKeepsPresence(t *testing.T) {
+	policy := DefaultPolicy
+	policy.TripRoundGranularity = 15 * time.Minute
+
+	// no break that could absorb the rounded trip time
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 5)},
+		{Type: EntryTypeLeave, Time: tim(13, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Presence(), result.WorkTime+result.BreakTime)
+	assert.Equal(t, dur(5, 0), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, dur(0, 10), result.TripCredit)
+	assert.Equal(t, dur(5, 10), result.AccountedWorkTime)
+	assert.Zero(t, result.AccountedBreakTime)
+
+	policy.TripsAsBreak = true
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Presence(), result.WorkTime+result.BreakTime)
+	assert.Zero(t, result.TripCredit)
+	assert.Equal(t, dur(4, 55), result.AccountedWorkTime)
+}
+
+func TestRoundingImpact(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 8), Source: "terminal"},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 52), Source: "terminal"},
+	}
+
+	impact, err := RoundingImpact(entries, 15*time.Minute, 5*time.Minute, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 10), impact)
+
+	impact, err = RoundingImpact(entries, 5*time.Minute, 15*time.Minute, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, -dur(0, 10), impact)
+
+	policy := DefaultPolicy
+	policy.SourceRounding = map[string]RoundConfig{"terminal": {Mode: RoundDown}}
+	impact, err = RoundingImpact(entries, 15*time.Minute, 5*time.Minute, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 0), impact)
+
+	_, err = RoundingImpact(nil, 15*time.Minute, 5*time.Minute, DefaultPolicy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestComputeResultSnapWindow(t *testing.T) {
+	policy := DefaultPolicy
+	policy.ShiftStart = dur(9, 0)
+	policy.SnapWindow = 5 * time.Minute
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 58)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 30)},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(9, 0), result.Start)
+	assert.Equal(t, dur(8, 0), result.AccountedWorkTime)
+
+	entries[0].Time = tim(9, 4)
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(9, 0), result.Start)
+
+	entries[0].Time = tim(8, 50)
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 50), result.Start)
+
+	policy.SnapWindow = 0
+	entries[0].Time = tim(8, 58)
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 58), result.Start)
+}
+
+func TestComputeResultRoundPresenceToMinute(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(9, 0).Add(30 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0).Add(30 * time.Second)},
+	}
+	presence := func(mode *RoundMode) time.Duration {
+		policy := DefaultPolicy
+		policy.RoundPresenceToMinute = mode
+		result, err := ComputeResult(entries, tim(18, 0), policy)
+		assert.NoError(t, err)
+		assert.Equal(t, result.Presence()-dur(0, 30), result.WorkTime)
+		return result.Presence()
+	}
+	mode := func(mode RoundMode) *RoundMode { return &mode }
+
+	assert.Equal(t, dur(8, 0), presence(nil))
+	assert.Equal(t, dur(7, 59), presence(mode(RoundDown)))
+	assert.Equal(t, dur(8, 1), presence(mode(RoundUp)))
+	assert.Equal(t, dur(8, 0), presence(mode(RoundNearest)))
+
+	// open days end at the rounded now
+	policy := DefaultPolicy
+	policy.RoundPresenceToMinute = mode(RoundUp)
+	result, err := ComputeResult(entries[:3], tim(15, 0).Add(10*time.Second), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(15, 1), result.End)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+)
+
+// Shift describes a planned working day. Only the time of day of Come and Leave is used, so a shift can serve as template for any day.
+type Shift struct {
+	Come  time.Time
+	Leave time.Time
+	Break time.Duration
+}
+
+// ShiftComparison contains the deviations of a day from its planned shift. All durations except BreakDelta are never negative.
+type ShiftComparison struct {
+	LateArrival    time.Duration
+	EarlyDeparture time.Duration
+	LateDeparture  time.Duration
+	// BreakDelta is the taken break minus the planned break.
+	BreakDelta time.Duration
+}
+
+// CompareToShift returns the deviations of the entries from the planned shift.
+//
+// Departures are only compared for closed days, while the break of open days is the break taken until now.
+func CompareToShift(entries []Entry, shift Shift, now time.Time) (ShiftComparison, error) {
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return ShiftComparison{}, err
+	}
+
+	day := midnight(times.Start)
+	come := day.Add(timeOfDay(shift.Come))
+	leave := day.Add(timeOfDay(shift.Leave))
+
+	comparison := ShiftComparison{
+		LateArrival: max(0, times.Start.Sub(come)),
+		BreakDelta:  times.BreakTime - shift.Break,
+	}
+	if entries[len(entries)-1].Type == EntryTypeLeave {
+		comparison.EarlyDeparture = max(0, leave.Sub(times.End))
+		comparison.LateDeparture = max(0, times.End.Sub(leave))
+	}
+	return comparison, nil
+}
+
+// StandardDayEntries returns the entries of a standard day on date following the times of day of the shift.
+//
+// The break of the shift is placed in the middle of the work time. No break entries are created for shifts without break.
+func StandardDayEntries(date time.Time, schedule Shift) []Entry {
+	day := midnight(date)
+	come := day.Add(timeOfDay(schedule.Come))
+	leave := day.Add(timeOfDay(schedule.Leave))
+	if schedule.Break <= 0 {
+		return []Entry{
+			{Type: EntryTypeCome, Time: come},
+			{Type: EntryTypeLeave, Time: leave},
+		}
+	}
+
+	breakStart := come.Add((leave.Sub(come) - schedule.Break) / 2).Truncate(time.Minute)
+	return []Entry{
+		{Type: EntryTypeCome, Time: come},
+		{Type: EntryTypeLeave, Time: breakStart},
+		{Type: EntryTypeCome, Time: breakStart.Add(schedule.Break)},
+		{Type: EntryTypeLeave, Time: leave},
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareToShift(t *testing.T) {
+	shift := Shift{Come: tim(8, 0), Leave: tim(16, 30), Break: dur(0, 30)}
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 10)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	comparison, err := CompareToShift(entries, shift, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, ShiftComparison{LateArrival: dur(0, 10)}, comparison)
+
+	entries[0].Time = tim(7, 50)
+	entries[2].Time = tim(12, 45)
+	entries[3].Time = tim(16, 0)
+	comparison, err = CompareToShift(entries, shift, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, ShiftComparison{EarlyDeparture: dur(0, 30), BreakDelta: dur(0, 15)}, comparison)
+
+	comparison, err = CompareToShift(entries[:3], shift, tim(14, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, ShiftComparison{BreakDelta: dur(0, 15)}, comparison)
+
+	_, err = CompareToShift(nil, shift, tim(14, 0))
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestCompareToShiftTemplate(t *testing.T) {
+	// an undated shift is applied to the day of the entries
+	template := Shift{Come: time.Time{}.Add(dur(9, 0)), Leave: time.Time{}.Add(dur(17, 30)), Break: dur(0, 30)}
+	entries := StandardDayEntries(date(2020, 3, 2), template)
+	entries[0].Time = entries[0].Time.Add(5 * time.Minute)
+	entries[3].Time = entries[3].Time.Add(20 * time.Minute)
+
+	comparison, err := CompareToShift(entries, template, date(2020, 3, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, ShiftComparison{LateArrival: dur(0, 5), LateDeparture: dur(0, 20)}, comparison)
+}
+
+func TestStandardDayEntries(t *testing.T) {
+	shift := Shift{Come: tim(9, 0), Leave: tim(17, 30), Break: dur(0, 30)}
+	entries := StandardDayEntries(date(2020, 3, 2), shift)
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: date(2020, 3, 2).Add(dur(9, 0))},
+		{Type: EntryTypeLeave, Time: date(2020, 3, 2).Add(dur(13, 0))},
+		{Type: EntryTypeCome, Time: date(2020, 3, 2).Add(dur(13, 30))},
+		{Type: EntryTypeLeave, Time: date(2020, 3, 2).Add(dur(17, 30))},
+	}, entries)
+
+	result, err := ComputeAccountedFromEntries(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.WorkTime)
+	assert.Equal(t, dur(0, 30), result.BreakTime)
+
+	shift.Break = 0
+	assert.Len(t, StandardDayEntries(date(2020, 3, 2), shift), 2)
+}
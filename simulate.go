@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// SimulateDelete returns the result as if the entry at index was removed. The given entries are not modified.
+//
+// Deleting a leave that is followed by a come also removes the come, so both working segments are merged.
+func SimulateDelete(entries []Entry, index int, now time.Time, policy Policy) (WorkTimeResult, error) {
+	if index < 0 || index >= len(entries) {
+		return WorkTimeResult{}, fmt.Errorf("entry index %d out of range", index)
+	}
+	end := index + 1
+	if entries[index].Type == EntryTypeLeave && end < len(entries) && entries[end].Type == EntryTypeCome {
+		end++
+	}
+	return ComputeResult(slices.Delete(slices.Clone(entries), index, end), now, policy)
+}
+
+// SimulateEdit returns the result as if the entry at index had the new time. The given entries are not modified.
+//
+// The edited entries are sorted by time again before computation.
+func SimulateEdit(entries []Entry, index int, newTime time.Time, now time.Time, policy Policy) (WorkTimeResult, error) {
+	if index < 0 || index >= len(entries) {
+		return WorkTimeResult{}, fmt.Errorf("entry index %d out of range", index)
+	}
+	edited := slices.Clone(entries)
+	edited[index].Time = newTime
+	slices.SortStableFunc(edited, func(a, b Entry) int {
+		return a.Time.Compare(b.Time)
+	})
+	return ComputeResult(edited, now, policy)
+}
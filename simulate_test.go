@@ -0,0 +1,56 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateDelete(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+	original := slices.Clone(entries)
+
+	result, err := SimulateDelete(entries, 3, tim(17, 30), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(17, 30), result.End)
+	assert.Equal(t, dur(9, 0), result.AccountedWorkTime)
+	assert.Equal(t, original, entries)
+
+	// removing the leave between two segments merges them
+	result, err = SimulateDelete(entries, 1, tim(17, 30), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, tim(8, 0), result.Start)
+	assert.Equal(t, tim(16, 30), result.End)
+	assert.Equal(t, dur(8, 30), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, dur(8, 0), result.AccountedWorkTime)
+	assert.Equal(t, original, entries)
+
+	_, err = SimulateDelete(entries, 4, tim(17, 30), DefaultPolicy)
+	assert.Error(t, err)
+}
+
+func TestSimulateEdit(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+	original := slices.Clone(entries)
+
+	result, err := SimulateEdit(entries, 0, tim(7, 30), tim(17, 30), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 30), result.AccountedWorkTime)
+	assert.Equal(t, original, entries)
+
+	// moving the first leave behind the following come breaks the sequence
+	_, err = SimulateEdit(entries, 1, tim(13, 0), tim(17, 30), DefaultPolicy)
+	assert.Error(t, err)
+}
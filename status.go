@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatusLine returns a single line describing the current state, the progress towards target and the owed break,
+// like "Working since 09:10 · 3h20m of 8h · break owed: 0m".
+func StatusLine(entries []Entry, target time.Duration, now time.Time, policy Policy) string {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return fmt.Sprintf("Status unavailable: %s", err.Error())
+	}
+	status, err := LiveBreakStatus(entries, now, policy)
+	if err != nil {
+		return fmt.Sprintf("Status unavailable: %s", err.Error())
+	}
+
+	last := entries[len(entries)-1]
+	return fmt.Sprintf("%s since %s · %s of %s · break owed: %s", stateLabel(entryState(last.Type)), last.Time.Format("15:04"),
+		formatDurationShort(result.AccountedWorkTime), formatDurationShort(target), formatDurationShort(status.Owed))
+}
+
+func stateLabel(state State) string {
+	switch state {
+	case StateWorking:
+		return "Working"
+	case StateTrip:
+		return "On trip"
+	case StateOnCall:
+		return "On call"
+	default:
+		return "Not working"
+	}
+}
+
+// formatDurationShort formats d like "3h20m", omitting zero components except for durations below one hour.
+func formatDurationShort(d time.Duration) string {
+	minutes := int(d.Minutes())
+	hours := minutes / 60
+	minutes = minutes - (60 * hours)
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	if minutes == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh%02dm", hours, minutes)
+}
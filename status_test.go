@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusLine(t *testing.T) {
+	entries := []Entry{{Type: EntryTypeCome, Time: tim(9, 10)}}
+	assert.Equal(t, "Working since 09:10 · 3h20m of 8h · break owed: 0m", StatusLine(entries, dur(8, 0), tim(12, 30), DefaultPolicy))
+	assert.Equal(t, "Working since 09:10 · 6h of 7h30m · break owed: 30m", StatusLine(entries, dur(7, 30), tim(15, 40), DefaultPolicy))
+
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+	assert.Equal(t, "Not working since 16:30 · 8h of 8h · break owed: 0m", StatusLine(entries, dur(8, 0), tim(18, 0), DefaultPolicy))
+
+	assert.Equal(t, "Status unavailable: no entries", StatusLine(nil, dur(8, 0), tim(18, 0), DefaultPolicy))
+}
+
+func TestFormatDurationShort(t *testing.T) {
+	assert.Equal(t, "0m", formatDurationShort(0))
+	assert.Equal(t, "45m", formatDurationShort(dur(0, 45)))
+	assert.Equal(t, "8h", formatDurationShort(dur(8, 0)))
+	assert.Equal(t, "3h05m", formatDurationShort(dur(3, 5)))
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"time"
+)
+
+// TargetProgress returns the achieved fraction of the target work time.
+//
+// The progress is limited to 1 unless allowOvertime is set. A target of zero is always fully achieved.
+func TargetProgress(accountedWork, target time.Duration, allowOvertime bool) float64 {
+	if target <= 0 {
+		return 1
+	}
+	progress := max(0, float64(accountedWork)/float64(target))
+	if !allowOvertime {
+		progress = min(1, progress)
+	}
+	return progress
+}
+
+// TargetMet returns true if the accounted work time reaches the target, tolerating a shortfall of up to grace.
+func TargetMet(accountedWork, target, grace time.Duration) bool {
+	return accountedWork >= target-grace
+}
+
+// OvertimeWithBreakGate returns the accounted work time exceeding target. Overtime only accrues if the mandated break
+// was taken, otherwise zero is returned.
+func OvertimeWithBreakGate(result WorkTimeResult, target time.Duration, policy Policy) time.Duration {
+	if ok, _ := CheckBreakCompliance(result, policy); !ok {
+		return 0
+	}
+	return max(0, result.AccountedWorkTime-target)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTargetProgress(t *testing.T) {
+	assert.Equal(t, 0.5, TargetProgress(dur(4, 0), dur(8, 0), false))
+	assert.Equal(t, 1.0, TargetProgress(dur(8, 0), dur(8, 0), false))
+	assert.Equal(t, 1.0, TargetProgress(dur(9, 36), dur(8, 0), false))
+	assert.Equal(t, 1.2, TargetProgress(dur(9, 36), dur(8, 0), true))
+	assert.Equal(t, 1.0, TargetProgress(dur(2, 0), 0, false))
+}
+
+func TestTargetMet(t *testing.T) {
+	assert.True(t, TargetMet(dur(7, 56), dur(8, 0), dur(0, 5)))
+	assert.True(t, TargetMet(dur(7, 55), dur(8, 0), dur(0, 5)))
+	assert.False(t, TargetMet(dur(7, 54), dur(8, 0), dur(0, 5)))
+	assert.False(t, TargetMet(dur(7, 56), dur(8, 0), 0))
+	assert.True(t, TargetMet(dur(8, 30), dur(8, 0), 0))
+}
+
+func TestOvertimeWithBreakGate(t *testing.T) {
+	result, err := ComputeResult([]Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 45)},
+		{Type: EntryTypeLeave, Time: tim(17, 30)},
+	}, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 45), OvertimeWithBreakGate(result, dur(8, 0), DefaultPolicy))
+	assert.Zero(t, OvertimeWithBreakGate(result, dur(9, 0), DefaultPolicy))
+
+	// skipping the break still results in accounted work beyond the target
+	result, err = ComputeResult([]Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 30)},
+	}, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(9, 0), result.AccountedWorkTime)
+	assert.Zero(t, OvertimeWithBreakGate(result, dur(8, 0), DefaultPolicy))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// StateNone denotes the time before, between and after working segments.
+	StateNone State = "none"
+	// StateWorking denotes the time while working in the company.
+	StateWorking State = "working"
+	// StateTrip denotes the time on a business trip.
+	StateTrip State = "trip"
+	// StateOnCall denotes the time on call outside of the company.
+	StateOnCall State = "oncall"
+)
+
+// State denotes the activity of an employee between two entries.
+type State string
+
+// StateSpan describes a time span in a single state.
+type StateSpan struct {
+	State State
+	Start time.Time
+	End   time.Time
+}
+
+// StateTimeline returns the spans of all states from the first entry until now.
+//
+// An open day ends with the current state at now, a closed day with a StateNone span from the last leave until now.
+func StateTimeline(entries []Entry, now time.Time) ([]StateSpan, error) {
+	if _, err := computeDayTimes(entries, now); err != nil {
+		return nil, err
+	}
+
+	spans := make([]StateSpan, 0, len(entries))
+	for i, entry := range entries {
+		end := now
+		if i+1 < len(entries) {
+			end = entries[i+1].Time
+		}
+		if end.Before(entry.Time) {
+			end = entry.Time
+		}
+		spans = append(spans, StateSpan{State: entryState(entry.Type), Start: entry.Time, End: end})
+	}
+	return spans, nil
+}
+
+func entryState(entryType EntryType) State {
+	switch entryType {
+	case EntryTypeCome:
+		return StateWorking
+	case EntryTypeTrip:
+		return StateTrip
+	case EntryTypeOnCall:
+		return StateOnCall
+	default:
+		return StateNone
+	}
+}
+
+// SplitAt returns the actual work and break times before and after boundary. Working intervals crossing the boundary are split.
+//
+// Accounted times are not computed, as policies apply to whole days only.
+func SplitAt(entries []Entry, boundary time.Time, now time.Time) (before, after WorkTimeResult, err error) {
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return WorkTimeResult{}, WorkTimeResult{}, err
+	}
+	if boundary.Before(times.Start) {
+		boundary = times.Start
+	}
+	if boundary.After(times.End) {
+		boundary = times.End
+	}
+
+	before = WorkTimeResult{Start: times.Start, End: boundary}
+	after = WorkTimeResult{Start: boundary, End: times.End}
+	for _, work := range workIntervals(entries, now) {
+		before.WorkTime += work.Overlap(Interval{Start: before.Start, End: before.End})
+		after.WorkTime += work.Overlap(Interval{Start: after.Start, End: after.End})
+	}
+	for _, onCall := range onCallIntervals(entries) {
+		before.OnCallTime += onCall.Overlap(Interval{Start: before.Start, End: before.End})
+		after.OnCallTime += onCall.Overlap(Interval{Start: after.Start, End: after.End})
+	}
+	before.BreakTime = before.Presence() - before.WorkTime - before.OnCallTime
+	after.BreakTime = after.Presence() - after.WorkTime - after.OnCallTime
+	return before, after, nil
+}
+
+// MaterializeAssumedBreaks returns a copy of entries with the break assumed by the policy inserted as explicit leave and come entries.
+//
+// The assumed break is the mandated break that was not taken, as far as it is deducted from the work time. It is centered
+// in the longest working segment outside of trips and limited to that segment. Open days and invalid entries are returned unchanged.
+func MaterializeAssumedBreaks(entries []Entry, policy Policy) []Entry {
+	entries = slices.Clone(entries)
+	if len(entries) == 0 || entries[len(entries)-1].Type != EntryTypeLeave {
+		return entries
+	}
+	end := entries[len(entries)-1].Time
+	result, err := ComputeResult(entries, end, policy)
+	if err != nil {
+		return entries
+	}
+	missingBreak := policy.RequiredBreakFor(result.WorkTime, result.BreakTime) - countedBreak(result, policy)
+	assumedBreak := min(missingBreak, result.WorkTime-result.AccountedWorkTime)
+	if assumedBreak <= 0 {
+		return entries
+	}
+
+	// segments from a come to the next leave, trip or on-call entry
+	longest := -1
+	for i := 0; i+1 < len(entries); i++ {
+		if entries[i].Type == EntryTypeCome && (longest < 0 || entries[i+1].Time.Sub(entries[i].Time) > entries[longest+1].Time.Sub(entries[longest].Time)) {
+			longest = i
+		}
+	}
+	if longest < 0 {
+		return entries
+	}
+	segment := Interval{Start: entries[longest].Time, End: entries[longest+1].Time}
+	assumedBreak = min(assumedBreak, segment.Duration())
+	breakStart := segment.Start.Add((segment.Duration() - assumedBreak) / 2)
+
+	materialized := slices.Insert(slices.Clone(entries), longest+1,
+		Entry{Type: EntryTypeLeave, Time: breakStart},
+		Entry{Type: EntryTypeCome, Time: breakStart.Add(assumedBreak)},
+	)
+	if _, err := computeDayTimes(materialized, end); err != nil {
+		return entries
+	}
+	return materialized
+}
+
+// WorkBeforeAfterBreak returns the work time before and after the longest break and the start of this break. Open days end at now.
+//
+// Days without break return all work time as before and a zero break start.
+func WorkBeforeAfterBreak(entries []Entry, now time.Time) (before, after time.Duration, breakStart time.Time, err error) {
+	var longest Interval
+	for _, b := range breakIntervals(entries) {
+		if b.Duration() > longest.Duration() {
+			longest = b
+		}
+	}
+	if longest.Duration() <= 0 {
+		times, err := computeDayTimes(entries, now)
+		if err != nil {
+			return 0, 0, time.Time{}, err
+		}
+		return times.WorkTime, 0, time.Time{}, nil
+	}
+
+	beforeResult, afterResult, err := SplitAt(entries, longest.Start, now)
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+	return beforeResult.WorkTime, afterResult.WorkTime, longest.Start, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateTimeline(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(11, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 0)},
+	}
+
+	spans, err := StateTimeline(entries, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []StateSpan{
+		{State: StateWorking, Start: tim(8, 0), End: tim(10, 0)},
+		{State: StateTrip, Start: tim(10, 0), End: tim(11, 30)},
+		{State: StateWorking, Start: tim(11, 30), End: tim(16, 0)},
+		{State: StateNone, Start: tim(16, 0), End: tim(18, 0)},
+	}, spans)
+
+	spans, err = StateTimeline(entries[:3], tim(12, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, StateSpan{State: StateWorking, Start: tim(11, 30), End: tim(12, 0)}, spans[2])
+
+	_, err = StateTimeline(entries[1:], tim(18, 0))
+	assert.Error(t, err)
+}
+
+func TestSplitAt(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	before, after, err := SplitAt(entries, tim(14, 0), tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 30), before.WorkTime)
+	assert.Equal(t, dur(0, 30), before.BreakTime)
+	assert.Equal(t, dur(3, 0), after.WorkTime)
+	assert.Equal(t, dur(0, 0), after.BreakTime)
+
+	before, after, err = SplitAt(entries, tim(12, 10), tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 0), before.WorkTime)
+	assert.Equal(t, dur(0, 10), before.BreakTime)
+	assert.Equal(t, dur(4, 30), after.WorkTime)
+	assert.Equal(t, dur(0, 20), after.BreakTime)
+}
+
+func TestMaterializeAssumedBreaks(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 45)},
+	}
+
+	materialized := MaterializeAssumedBreaks(entries, DefaultPolicy)
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 30)},
+		{Type: EntryTypeCome, Time: tim(13, 15)},
+		{Type: EntryTypeLeave, Time: tim(17, 45)},
+	}, materialized)
+	assert.Len(t, entries, 2)
+
+	original, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	result, err := ComputeResult(materialized, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, original.AccountedWorkTime, result.AccountedWorkTime)
+	assert.Equal(t, result.WorkTime, result.AccountedWorkTime)
+
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(9, 0)},
+		{Type: EntryTypeCome, Time: tim(9, 10)},
+		{Type: EntryTypeLeave, Time: tim(15, 30)},
+	}
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(9, 0)},
+		{Type: EntryTypeCome, Time: tim(9, 10)},
+		{Type: EntryTypeLeave, Time: tim(12, 10)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(15, 30)},
+	}, MaterializeAssumedBreaks(entries, DefaultPolicy))
+
+	assert.Equal(t, entries[:3], MaterializeAssumedBreaks(entries[:3], DefaultPolicy))
+	assert.Equal(t, entries[:2], MaterializeAssumedBreaks(entries[:2], DefaultPolicy))
+}
+
+func TestMaterializeAssumedBreaksCapped(t *testing.T) {
+	// the maximum work time is no break
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(19, 0)},
+	}
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 37).Add(30 * time.Second)},
+		{Type: EntryTypeCome, Time: tim(13, 22).Add(30 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(19, 0)},
+	}, MaterializeAssumedBreaks(entries, DefaultPolicy))
+}
+
+func TestMaterializeAssumedBreaksTrips(t *testing.T) {
+	// the trip is the longest segment, but the break is placed in the longest segment in the company
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(9, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	materialized := MaterializeAssumedBreaks(entries, DefaultPolicy)
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(9, 0)},
+		{Type: EntryTypeCome, Time: tim(15, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 45)},
+		{Type: EntryTypeCome, Time: tim(16, 15)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}, materialized)
+
+	// the break is limited to the segment, keeping the entries sorted
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(8, 20)},
+		{Type: EntryTypeCome, Time: tim(15, 0)},
+		{Type: EntryTypeLeave, Time: tim(15, 20)},
+	}
+	materialized = MaterializeAssumedBreaks(entries, DefaultPolicy)
+	assert.True(t, slices.IsSortedFunc(materialized, func(a, b Entry) int { return a.Time.Compare(b.Time) }))
+	_, err := ComputeResult(materialized, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+}
+
+func TestWorkBeforeAfterBreak(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 10)},
+		{Type: EntryTypeLeave, Time: tim(12, 15)},
+		{Type: EntryTypeCome, Time: tim(13, 0)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+	}
+
+	before, after, breakStart, err := WorkBeforeAfterBreak(entries, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 5), before)
+	assert.Equal(t, dur(3, 30), after)
+	assert.Equal(t, tim(12, 15), breakStart)
+
+	before, after, breakStart, err = WorkBeforeAfterBreak(entries[:1], tim(11, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(3, 0), before)
+	assert.Equal(t, dur(0, 0), after)
+	assert.True(t, breakStart.IsZero())
+
+	_, _, _, err = WorkBeforeAfterBreak(nil, tim(11, 0))
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time and timers to live computations.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is a Clock using the local system time.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WatchRemaining emits the remaining accounted work time to reach target on every interval tick.
+//
+// The returned channel is closed when the context is cancelled or the entries can not be computed.
+func WatchRemaining(ctx context.Context, entries []Entry, target time.Duration, interval time.Duration, clock Clock) <-chan time.Duration {
+	ch := make(chan time.Duration)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-clock.After(interval):
+			}
+
+			remaining, err := remainingWorkTime(entries, target, clock.Now())
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- remaining:
+			}
+		}
+	}()
+	return ch
+}
+
+func remainingWorkTime(entries []Entry, target time.Duration, now time.Time) (time.Duration, error) {
+	result, err := ComputeResult(entries, now, DefaultPolicy)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, target-result.AccountedWorkTime), nil
+}
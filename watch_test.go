@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+	ticks chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, ticks: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.ticks
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mutex.Unlock()
+	c.ticks <- now
+}
+
+func TestWatchRemaining(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := newFakeClock(tim(12, 0))
+	entries := []Entry{{Type: EntryTypeCome, Time: tim(8, 0)}}
+	ch := WatchRemaining(ctx, entries, dur(8, 0), time.Minute, clock)
+
+	clock.Advance(dur(1, 0))
+	assert.Equal(t, dur(3, 0), <-ch)
+	clock.Advance(dur(1, 0))
+	assert.Equal(t, dur(2, 0), <-ch)
+	clock.Advance(dur(2, 0))
+	assert.Equal(t, dur(0, 30), <-ch)
+
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WeekResult contains the accounted times of all days in a week.
+type WeekResult struct {
+	Days      []DayResult
+	WorkTime  time.Duration
+	BreakTime time.Duration
+}
+
+// CheckWeeklyMax returns whether the accounted work time of the week is within maxWeekly and the excess otherwise.
+func CheckWeeklyMax(week WeekResult, maxWeekly time.Duration) (bool, time.Duration) {
+	if week.WorkTime <= maxWeekly {
+		return true, 0
+	}
+	return false, week.WorkTime - maxWeekly
+}
+
+// ISOWeek identifies a week according to ISO 8601.
+type ISOWeek struct {
+	Year int
+	Week int
+}
+
+// ComputeByISOWeek returns the results of all days grouped by ISO week.
+func ComputeByISOWeek(days map[time.Time][]Entry, policy Policy) (map[ISOWeek]WeekResult, error) {
+	weeks := make(map[ISOWeek]WeekResult)
+	for date, entries := range days {
+		if len(entries) == 0 {
+			continue
+		}
+		day, err := computeDayResult(date, entries, time.Now(), policy)
+		if err != nil {
+			return nil, err
+		}
+
+		var key ISOWeek
+		key.Year, key.Week = date.ISOWeek()
+		week := weeks[key]
+		week.Days = append(week.Days, day)
+		week.WorkTime += day.WorkTime
+		week.BreakTime += day.BreakTime
+		weeks[key] = week
+	}
+
+	for _, week := range weeks {
+		slices.SortFunc(week.Days, func(a, b DayResult) int {
+			return a.Date.Compare(b.Date)
+		})
+	}
+	return weeks, nil
+}
+
+func computeDayResult(date time.Time, entries []Entry, now time.Time, policy Policy) (DayResult, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return DayResult{}, fmt.Errorf("failed to compute %s: %s", date.Format("2006-01-02"), err.Error())
+	}
+	return DayResult{Date: date, WorkTime: result.AccountedWorkTime, BreakTime: result.AccountedBreakTime}, nil
+}
+
+// ComputeAllDays returns the results of a sorted list of entries spanning multiple days in date order.
+//
+// Entries are grouped by their calendar date. Only the last day may be open and ends at now.
+func ComputeAllDays(entries []Entry, now time.Time, policy Policy) ([]DayResult, error) {
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.Before(entries[i-1].Time) {
+			return nil, fmt.Errorf("%w: %s entry at index %d", ErrUnsortedEntries, entries[i].Type, i)
+		}
+	}
+
+	var days []DayResult
+	for len(entries) > 0 {
+		date := midnight(entries[0].Time)
+		n := 1
+		for n < len(entries) && midnight(entries[n].Time).Equal(date) {
+			n++
+		}
+		if n < len(entries) && entries[n-1].Type != EntryTypeLeave {
+			return nil, fmt.Errorf("day %s is not closed", date.Format("2006-01-02"))
+		}
+
+		day, err := computeDayResult(date, entries[:n], now, policy)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+		entries = entries[n:]
+	}
+	return days, nil
+}
+
+// CheckConsecutiveWorkdays returns the dates of all days that exceed a run of maxConsecutive worked days.
+//
+// Days must be sorted by date. A day is worked if it has any work time. Days without work time and missing dates end a run.
+func CheckConsecutiveWorkdays(days []DayResult, maxConsecutive int) ([]time.Time, error) {
+	if maxConsecutive < 1 {
+		return nil, fmt.Errorf("at least one consecutive workday must be allowed")
+	}
+
+	var exceeding []time.Time
+	run := 0
+	for i, day := range days {
+		if i > 0 && !midnight(days[i-1].Date).Before(midnight(day.Date)) {
+			return nil, fmt.Errorf("%w: day %s at index %d", ErrUnsortedDays, day.Date.Format("2006-01-02"), i)
+		}
+
+		if day.WorkTime <= 0 {
+			run = 0
+			continue
+		}
+		if i > 0 && !midnight(days[i-1].Date).AddDate(0, 0, 1).Equal(midnight(day.Date)) {
+			run = 0
+		}
+		run++
+		if run > maxConsecutive {
+			exceeding = append(exceeding, day.Date)
+		}
+	}
+	return exceeding, nil
+}
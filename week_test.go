@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWeeklyMax(t *testing.T) {
+	ok, excess := CheckWeeklyMax(WeekResult{WorkTime: dur(52, 0)}, DefaultPolicy.WeeklyMax)
+	assert.False(t, ok)
+	assert.Equal(t, dur(4, 0), excess)
+
+	ok, excess = CheckWeeklyMax(WeekResult{WorkTime: dur(40, 0)}, DefaultPolicy.WeeklyMax)
+	assert.True(t, ok)
+	assert.Equal(t, dur(0, 0), excess)
+}
+
+func TestComputeByISOWeek(t *testing.T) {
+	day := func(date time.Time, hours int) []Entry {
+		return []Entry{
+			{Type: EntryTypeCome, Time: date.Add(dur(8, 0))},
+			{Type: EntryTypeLeave, Time: date.Add(dur(8+hours, 0))},
+		}
+	}
+
+	days := map[time.Time][]Entry{
+		date(2021, time.January, 1):   day(date(2021, time.January, 1), 4),
+		date(2020, time.December, 31): day(date(2020, time.December, 31), 5),
+		date(2021, time.January, 4):   day(date(2021, time.January, 4), 6),
+	}
+
+	weeks, err := ComputeByISOWeek(days, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Len(t, weeks, 2)
+
+	week := weeks[ISOWeek{Year: 2020, Week: 53}]
+	assert.Equal(t, dur(9, 0), week.WorkTime)
+	assert.Equal(t, []time.Time{date(2020, time.December, 31), date(2021, time.January, 1)}, []time.Time{week.Days[0].Date, week.Days[1].Date})
+
+	assert.Equal(t, dur(6, 0), weeks[ISOWeek{Year: 2021, Week: 1}].WorkTime)
+}
+
+func TestComputeAllDays(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 30)},
+		{Type: EntryTypeCome, Time: tim(9, 0).AddDate(0, 0, 1)},
+		{Type: EntryTypeLeave, Time: tim(18, 0).AddDate(0, 0, 1)},
+		{Type: EntryTypeCome, Time: tim(7, 0).AddDate(0, 0, 3)},
+	}
+
+	days, err := ComputeAllDays(entries, tim(12, 0).AddDate(0, 0, 3), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []DayResult{
+		{Date: date(2019, time.November, 1), WorkTime: dur(8, 0), BreakTime: dur(0, 30)},
+		{Date: date(2019, time.November, 2), WorkTime: dur(8, 30), BreakTime: dur(0, 30)},
+		{Date: date(2019, time.November, 4), WorkTime: dur(5, 0), BreakTime: dur(0, 0)},
+	}, days)
+
+	days, err = ComputeAllDays(nil, tim(12, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Empty(t, days)
+
+	_, err = ComputeAllDays(append(entries[:4:4], entries[6]), tim(12, 0).AddDate(0, 0, 3), DefaultPolicy)
+	assert.NoError(t, err)
+	_, err = ComputeAllDays(append(entries[:3:3], entries[4:]...), tim(12, 0).AddDate(0, 0, 3), DefaultPolicy)
+	assert.Error(t, err)
+	_, err = ComputeAllDays([]Entry{entries[4], entries[0]}, tim(12, 0).AddDate(0, 0, 3), DefaultPolicy)
+	assert.ErrorIs(t, err, ErrUnsortedEntries)
+}
+
+func TestCheckConsecutiveWorkdays(t *testing.T) {
+	var days []DayResult
+	for day := 1; day <= 7; day++ {
+		days = append(days, DayResult{Date: date(2020, 3, day), WorkTime: dur(8, 0)})
+	}
+
+	exceeding, err := CheckConsecutiveWorkdays(days, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{date(2020, 3, 7)}, exceeding)
+
+	// a day off ends the run
+	days[3].WorkTime = 0
+	exceeding, err = CheckConsecutiveWorkdays(days, 3)
+	assert.NoError(t, err)
+	assert.Empty(t, exceeding)
+
+	// so does a missing date
+	exceeding, err = CheckConsecutiveWorkdays(append(days[4:], DayResult{Date: date(2020, 3, 9), WorkTime: dur(8, 0)}), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, exceeding)
+
+	_, err = CheckConsecutiveWorkdays([]DayResult{days[1], days[0]}, 6)
+	assert.ErrorIs(t, err, ErrUnsortedDays)
+
+	_, err = CheckConsecutiveWorkdays(days, 0)
+	assert.Error(t, err)
+}
@@ -12,6 +12,8 @@ const (
 	EntryTypeLeave EntryType = "leave"
 	// EntryTypeTrip denotes an entry for a short business trip.
 	EntryTypeTrip EntryType = "trip"
+	// EntryTypeOnCall denotes an entry for being on call outside of the company.
+	EntryTypeOnCall EntryType = "oncall"
 )
 
 var (
@@ -19,15 +21,45 @@ var (
 	ErrNoEntries = fmt.Errorf("no entries")
 	// ErrMaxTimeReached is returned when a solution would exceed the maximum working time.
 	ErrMaxTimeReached = fmt.Errorf("a maximum working time of 10 hours per day is allowed")
-	// TODO: implement this:
 	// ErrOutOfBusinessHours is returned when a solution is outside of the allowed business working hours.
-	//ErrOutOfBusinessHours = fmt.Errorf("business hours are from 6:30 to 21:00")
+	ErrOutOfBusinessHours = fmt.Errorf("business hours are from 6:30 to 21:00")
+	// ErrTripOutsideWork is returned when a trip is started while not working.
+	ErrTripOutsideWork = fmt.Errorf("trips can only be started while working")
+	// ErrUnsortedEntries is returned when entries are not sorted by time.
+	ErrUnsortedEntries = fmt.Errorf("entries are not sorted by time")
+	// ErrUnsortedDays is returned when the results of multiple days are not sorted by date.
+	ErrUnsortedDays = fmt.Errorf("days are not sorted by date")
+	// ErrMixedDays is returned when entries belong to different days.
+	ErrMixedDays = fmt.Errorf("list of entries must be for the same day")
+	// ErrOpenDay is returned when a closed day is required, but the last entry is not a leave.
+	ErrOpenDay = fmt.Errorf("day is not closed by a leave entry")
+	// ErrInvalidSequence is returned when the entries do not form a valid sequence of come, leave and trip entries.
+	ErrInvalidSequence = fmt.Errorf("invalid sequence of entries")
+	// ErrBreakNotCompliant is returned when the taken break is shorter than the mandated break.
+	ErrBreakNotCompliant = fmt.Errorf("break is shorter than mandated")
+	// ErrContinuousWorkExceeded is returned when a single working segment exceeds the maximum continuous work time.
+	ErrContinuousWorkExceeded = fmt.Errorf("continuous work time exceeded")
 )
 
 // Entry describes an entry for coming or leaving to a given time.
 type Entry struct {
 	Type EntryType
 	Time time.Time
+	// Source optionally names the system the entry was recorded with, like "terminal" or "web".
+	Source string
+	// Edited marks entries that have been corrected manually. It does not affect any computation.
+	Edited   bool
+	EditedBy string
+}
+
+// HasEdits returns true if any of the entries has been corrected manually.
+func HasEdits(entries []Entry) bool {
+	for _, entry := range entries {
+		if entry.Edited {
+			return true
+		}
+	}
+	return false
 }
 
 // EntryType denotes whether an entry is for coming or leaving the company.
@@ -35,40 +67,70 @@ type EntryType string
 
 // ComputeWorkTime returns the actual work time, start time and taken break from a set of entries.
 func ComputeWorkTime(entries []Entry) (time.Duration, time.Time, time.Duration, error) {
+	return computeWorkTime(entries, time.Now())
+}
+
+func computeWorkTime(entries []Entry, now time.Time) (time.Duration, time.Time, time.Duration, error) {
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return 0, time.Unix(0, 0), 0, err
+	}
+	return times.WorkTime, times.Start, times.BreakTime, nil
+}
+
+// dayTimes contains the actual times of a day as computed by the entry state machine.
+type dayTimes struct {
+	Start     time.Time
+	End       time.Time
+	WorkTime  time.Duration
+	BreakTime time.Duration
+	// TripTime is the part of the work time spent on trips.
+	TripTime time.Duration
+	// OnCallTime is the time spent on call. It is neither part of the work time nor of the break time.
+	OnCallTime time.Duration
+}
+
+func computeDayTimes(entries []Entry, now time.Time) (dayTimes, error) {
 	if len(entries) == 0 {
-		return 0, time.Unix(0, 0), 0, ErrNoEntries
+		return dayTimes{}, ErrNoEntries
 	}
 
 	//TODO sort entries by time
 
+	if entries[0].Type == EntryTypeTrip {
+		return dayTimes{}, fmt.Errorf("%w at index %d", ErrTripOutsideWork, 0)
+	}
 	if entries[0].Type != EntryTypeCome {
-		return 0, time.Unix(0, 0), 0, fmt.Errorf("did you work all night?")
+		return dayTimes{}, fmt.Errorf("did you work all night?")
 	}
 	if (entries[0].Time.Year() != entries[len(entries)-1].Time.Year()) || (entries[0].Time.Month() != entries[len(entries)-1].Time.Month()) || (entries[0].Time.Day() != entries[len(entries)-1].Time.Day()) {
-		return 0, time.Unix(0, 0), 0, fmt.Errorf("list of entries must be for the same day")
+		return dayTimes{}, ErrMixedDays
 	}
 
 	if entries[len(entries)-1].Type != EntryTypeLeave {
 		//TODO check entry is for today
 
 		// current in working time slot? end it by virtual leave entry at the current time for live computation
-		entries = append(entries, Entry{Type: EntryTypeLeave, Time: time.Now()})
+		entries = append(entries[:len(entries):len(entries)], Entry{Type: EntryTypeLeave, Time: now})
 	}
 
 	stateNone := 0
 	stateWorking := 1
 	stateTrip := 2
+	stateOnCall := 3
 	state := stateNone
 
-	var workTime time.Duration
-	var lastCome time.Time
+	var workTime, tripTime, onCallTime time.Duration
+	var lastCome, lastTrip, lastOnCall time.Time
 	for i := 0; i < len(entries); i++ {
 		if state == stateNone {
 			if entries[i].Type == EntryTypeCome {
 				lastCome = entries[i].Time
 				state = stateWorking
+			} else if entries[i].Type == EntryTypeTrip {
+				return dayTimes{}, fmt.Errorf("%w at index %d", ErrTripOutsideWork, i)
 			} else {
-				return 0, time.Unix(0, 0), 0, fmt.Errorf("1unexpected entry %q at index %d", entries[i].Type, i)
+				return dayTimes{}, fmt.Errorf("1unexpected entry %q at index %d", entries[i].Type, i)
 			}
 
 		} else if state == stateWorking {
@@ -76,23 +138,46 @@ func ComputeWorkTime(entries []Entry) (time.Duration, time.Time, time.Duration,
 				workTime += entries[i].Time.Sub(lastCome)
 				state = stateNone
 			} else if entries[i].Type == EntryTypeTrip {
+				lastTrip = entries[i].Time
 				state = stateTrip
+			} else if entries[i].Type == EntryTypeOnCall {
+				workTime += entries[i].Time.Sub(lastCome)
+				lastOnCall = entries[i].Time
+				state = stateOnCall
 			} else {
-				return 0, time.Unix(0, 0), 0, fmt.Errorf("2unexpected entry %q at index %d", entries[i].Type, i)
+				return dayTimes{}, fmt.Errorf("2unexpected entry %q at index %d", entries[i].Type, i)
 			}
 
 		} else if state == stateTrip {
 			if entries[i].Type == EntryTypeCome {
+				tripTime += entries[i].Time.Sub(lastTrip)
 				state = stateWorking
 			} else {
-				return 0, time.Unix(0, 0), 0, fmt.Errorf("3unexpected entry %q at index %d", entries[i].Type, i)
+				return dayTimes{}, fmt.Errorf("3unexpected entry %q at index %d", entries[i].Type, i)
+			}
+
+		} else if state == stateOnCall {
+			if entries[i].Type == EntryTypeCome {
+				onCallTime += entries[i].Time.Sub(lastOnCall)
+				lastCome = entries[i].Time
+				state = stateWorking
+			} else {
+				return dayTimes{}, fmt.Errorf("4unexpected entry %q at index %d", entries[i].Type, i)
 			}
 		}
 	}
 
+	// on-call time is neither work nor break
 	presenceTime := entries[len(entries)-1].Time.Sub(entries[0].Time)
-	breakTime := presenceTime - workTime
-	return workTime, entries[0].Time, breakTime, nil
+	breakTime := presenceTime - workTime - onCallTime
+	return dayTimes{
+		Start:      entries[0].Time,
+		End:        entries[len(entries)-1].Time,
+		WorkTime:   workTime,
+		BreakTime:  breakTime,
+		TripTime:   tripTime,
+		OnCallTime: onCallTime,
+	}, nil
 }
 
 // ComputeAccountedWorkTime returns the accounted work and break times according to country policies.
@@ -101,38 +186,266 @@ func ComputeAccountedWorkTime(workTime, breakTime time.Duration) (time.Duration,
 	// 08:08 - 17:38 -> 09:00 work, 00:32 break
 	// after 6 hours, the work time only increases when the break time is 30
 	// after 9 hours, the work time only increases when the break time is 45
+	workTime, breakTime = DefaultPolicy.AccountedWorkTime(workTime, breakTime)
+	return workTime, breakTime, nil
+}
 
-	if workTime > (6 * time.Hour) {
-		if breakTime < (30 * time.Minute) {
-			if (workTime + breakTime - 6*time.Hour) < (30 * time.Minute) {
-				breakTime = workTime + breakTime - 6*time.Hour
-				workTime = 6 * time.Hour
-			} else {
-				workTime = workTime + breakTime - 30*time.Minute
-				breakTime = 30 * time.Minute
-			}
+// AccountedResult contains the accounted work and break time of a day.
+type AccountedResult struct {
+	WorkTime  time.Duration
+	BreakTime time.Duration
+}
+
+// ComputeAccountedFromEntries returns the accounted work and break times for a set of entries according to the default policy.
+func ComputeAccountedFromEntries(entries []Entry) (AccountedResult, error) {
+	result, err := ComputeResult(entries, time.Now(), DefaultPolicy)
+	if err != nil {
+		return AccountedResult{}, err
+	}
+	return AccountedResult{WorkTime: result.AccountedWorkTime, BreakTime: result.AccountedBreakTime}, nil
+}
+
+// EstimateAccountedFromBreakCount returns an estimate of the accounted times for sources that only report the number of breaks.
+//
+// Every break is assumed to take avgBreak, so the result is only as exact as this assumption.
+func EstimateAccountedFromBreakCount(presence time.Duration, breakCount int, avgBreak time.Duration, policy Policy) AccountedResult {
+	breakTime := min(presence, time.Duration(max(0, breakCount))*avgBreak)
+	workTime, breakTime := policy.AccountedWorkTime(presence-breakTime, breakTime)
+	return AccountedResult{WorkTime: workTime, BreakTime: breakTime}
+}
+
+// ToEntries returns a representative list of entries on date starting at the time of day of start.
+//
+// The accounted break is inserted as a single break in the middle of the work time.
+func (r AccountedResult) ToEntries(date time.Time, start time.Time) []Entry {
+	startTime := CombineDate(date, start)
+	if r.BreakTime <= 0 {
+		return []Entry{
+			{Type: EntryTypeCome, Time: startTime},
+			{Type: EntryTypeLeave, Time: startTime.Add(r.WorkTime)},
 		}
 	}
 
-	if workTime > (9 * time.Hour) {
-		if breakTime < (45 * time.Minute) {
-			if (workTime + breakTime - 9*time.Hour) < (45 * time.Minute) {
-				breakTime = workTime + breakTime - 9*time.Hour
-				workTime = 9 * time.Hour
-			} else {
-				workTime = workTime + breakTime - 45*time.Minute
-				breakTime = 45 * time.Minute
-			}
+	breakStart := startTime.Add(r.WorkTime / 2)
+	return []Entry{
+		{Type: EntryTypeCome, Time: startTime},
+		{Type: EntryTypeLeave, Time: breakStart},
+		{Type: EntryTypeCome, Time: breakStart.Add(r.BreakTime)},
+		{Type: EntryTypeLeave, Time: startTime.Add(r.WorkTime).Add(r.BreakTime)},
+	}
+}
+
+// WorkTimeResult contains the actual and accounted times of a day.
+type WorkTimeResult struct {
+	Start     time.Time
+	End       time.Time
+	WorkTime  time.Duration
+	BreakTime time.Duration
+	// TripTime is the time spent on trips. It is part of the work time unless trips are counted as break.
+	TripTime time.Duration
+	// OnCallTime is the time spent on call. Only the part credited by the on-call factor of the policy is work time.
+	OnCallTime time.Duration
+	// UncreditedOnCallTime is the part of the on-call time that is neither work nor break time.
+	UncreditedOnCallTime time.Duration
+	// TripCredit is the work time credited in addition to the actual trip time by rounding up trips.
+	TripCredit time.Duration
+	// LongestBreak is the longest single break between a leave and the following come.
+	LongestBreak       time.Duration
+	AccountedWorkTime  time.Duration
+	AccountedBreakTime time.Duration
+	// ExcessBreakTime is the break exceeding the maximum credited break of the policy.
+	ExcessBreakTime time.Duration
+	// ProjectedWork is only set by LiveResult. It is the work time at the end of the day when continuing to the target,
+	// reduced by the mandated break that is still to be taken.
+	ProjectedWork time.Duration
+}
+
+// Presence returns the time between first come and last leave.
+func (r WorkTimeResult) Presence() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// preprocessEntries returns a copy of entries with the snapping, rounding and short break policies applied, together
+// with the likewise rounded end of open days.
+func preprocessEntries(entries []Entry, now time.Time, policy Policy) ([]Entry, time.Time) {
+	entries = snapEntries(entries, policy)
+	entries = roundEntries(entries, policy)
+	entries, now = roundPresence(entries, now, policy)
+	entries = removeShortBreaks(entries, policy.IgnoreBreaksBelow)
+	return entries, now
+}
+
+// ComputeResult returns the actual and accounted times for a set of entries. Open days end at now.
+func ComputeResult(entries []Entry, now time.Time, policy Policy) (WorkTimeResult, error) {
+	entries, now = preprocessEntries(entries, now, policy)
+	times, err := computeDayTimes(entries, now)
+	if err != nil {
+		return WorkTimeResult{}, err
+	}
+
+	// rounded trips are credited on top of the actual work time
+	var tripCredit time.Duration
+	if policy.TripRoundGranularity > 0 && !policy.TripsAsBreak {
+		for _, trip := range tripIntervals(entries) {
+			tripCredit += RoundConfig{Granularity: policy.TripRoundGranularity, Mode: RoundUp}.RoundDuration(trip.Duration()) - trip.Duration()
 		}
 	}
 
-	// are the corrected values still above 10h?
-	if workTime > (10 * time.Hour) {
-		breakTime = workTime + breakTime - 10*time.Hour
-		workTime = 10 * time.Hour
+	onCallWorkTime := time.Duration(float64(times.OnCallTime) * policy.OnCallFactor)
+	uncreditedOnCallTime := times.OnCallTime - onCallWorkTime
+	times.WorkTime += onCallWorkTime
+
+	// breaks directly after arrival are counted as work
+	countableFrom := times.Start.Add(policy.EarliestCountableBreak)
+	var longestBreak time.Duration
+	for _, b := range breakIntervals(entries) {
+		uncounted := b.Overlap(Interval{Start: times.Start, End: countableFrom})
+		times.WorkTime += uncounted
+		times.BreakTime -= uncounted
+		longestBreak = max(longestBreak, b.Duration()-uncounted)
 	}
 
-	return workTime, breakTime, nil
+	if policy.TripsAsBreak {
+		times.WorkTime -= times.TripTime
+		times.BreakTime += times.TripTime
+	}
+
+	// trips are never deducted by fixed windows, as they are either work elsewhere or already counted as break
+	for _, window := range policy.FixedBreakWindows {
+		var workInWindow time.Duration
+		for _, work := range workIntervals(entries, now) {
+			workInWindow += work.Overlap(window.On(times.Start))
+		}
+		for _, trip := range tripIntervals(entries) {
+			workInWindow -= trip.Overlap(window.On(times.Start))
+		}
+		times.WorkTime -= workInWindow
+		times.BreakTime += workInWindow
+	}
+
+	accountedWorkTime, accountedBreakTime, excessBreakTime := accountTimes(times.WorkTime, times.BreakTime, longestBreak, tripCredit, policy)
+
+	return WorkTimeResult{
+		Start:                times.Start,
+		End:                  times.End,
+		WorkTime:             times.WorkTime,
+		BreakTime:            times.BreakTime,
+		TripTime:             times.TripTime,
+		OnCallTime:           times.OnCallTime,
+		UncreditedOnCallTime: uncreditedOnCallTime,
+		TripCredit:           tripCredit,
+		LongestBreak:         longestBreak,
+		AccountedWorkTime:    accountedWorkTime,
+		AccountedBreakTime:   accountedBreakTime,
+		ExcessBreakTime:      excessBreakTime,
+	}, nil
+}
+
+// accountTimes returns the accounted work and break time and the excess break for the actual times of a day.
+//
+// The credit is added to the accounted work time without reducing the break.
+func accountTimes(workTime, breakTime, longestBreak, credit time.Duration, policy Policy) (accountedWorkTime, accountedBreakTime, excessBreakTime time.Duration) {
+	// only a single contiguous break is able to satisfy the break rules if required
+	ruleBreakTime := breakTime
+	if policy.RequireContiguousBreak {
+		ruleBreakTime = longestBreak
+	}
+	accountedWorkTime, _ = policy.AccountedWorkTime(workTime, ruleBreakTime)
+	accountedBreakTime = workTime + breakTime - accountedWorkTime
+	accountedWorkTime += credit
+
+	// the minimum shift is credited on top without reducing the break
+	accountedWorkTime = max(accountedWorkTime, policy.MinShiftLength)
+
+	if policy.MaxCreditedBreak > 0 && accountedBreakTime > policy.MaxCreditedBreak {
+		excessBreakTime = accountedBreakTime - policy.MaxCreditedBreak
+		accountedBreakTime = policy.MaxCreditedBreak
+	}
+	return accountedWorkTime, accountedBreakTime, excessBreakTime
+}
+
+// LiveResult returns the result like ComputeResult and projects the work time when continuing to the target.
+//
+// For open days, ProjectedWork is the target, or the current work time if already beyond the target, minus the break
+// that is mandated at that work time but not taken yet.
+// For closed days, it equals the accounted work time.
+func LiveResult(entries []Entry, now time.Time, target time.Duration, policy Policy) (WorkTimeResult, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return WorkTimeResult{}, err
+	}
+
+	if entries[len(entries)-1].Type == EntryTypeLeave {
+		result.ProjectedWork = result.AccountedWorkTime
+		return result, nil
+	}
+	projectedWork := max(result.WorkTime, target)
+	requiredBreak := policy.RequiredBreakFor(projectedWork, result.BreakTime)
+	result.ProjectedWork = projectedWork - max(0, requiredBreak-countedBreak(result, policy))
+	return result, nil
+}
+
+// ComputeLenient returns the result like ComputeResult, but reports anomalies as warnings instead of failing.
+//
+// Entries out of business hours are reported with a warning.
+func ComputeLenient(entries []Entry, now time.Time, policy Policy) (WorkTimeResult, []string, error) {
+	result, err := ComputeResult(entries, now, policy)
+	if err != nil {
+		return WorkTimeResult{}, nil, err
+	}
+
+	var warnings []string
+	for i, entry := range entries {
+		if !policy.InBusinessHours(entry.Time) {
+			warnings = append(warnings, fmt.Sprintf("%s entry at %s (index %d): %s", entry.Type, entry.Time.Format("15:04"), i, ErrOutOfBusinessHours.Error()))
+		}
+	}
+	return result, warnings, nil
+}
+
+// ComputeStrict returns the result of a closed day and fails on the first violation instead of correcting anything.
+//
+// The entries must be sorted, on the same day, closed by a leave, form a valid sequence, lie within business hours
+// and comply with the maximum work time, the maximum continuous work time and the mandated break. Errors wrap the sentinel error of the failed validation.
+func ComputeStrict(entries []Entry, policy Policy) (WorkTimeResult, error) {
+	if len(entries) == 0 {
+		return WorkTimeResult{}, ErrNoEntries
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Time.Before(entries[i-1].Time) {
+			return WorkTimeResult{}, fmt.Errorf("%w: %s entry at index %d", ErrUnsortedEntries, entries[i].Type, i)
+		}
+	}
+	first, last := entries[0].Time, entries[len(entries)-1].Time
+	if first.Year() != last.Year() || first.YearDay() != last.YearDay() {
+		return WorkTimeResult{}, fmt.Errorf("%w: %s and %s", ErrMixedDays, first.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	if entries[len(entries)-1].Type != EntryTypeLeave {
+		return WorkTimeResult{}, fmt.Errorf("%w: last entry is %q", ErrOpenDay, entries[len(entries)-1].Type)
+	}
+	if _, err := computeDayTimes(entries, last); err != nil {
+		return WorkTimeResult{}, fmt.Errorf("%w: %w", ErrInvalidSequence, err)
+	}
+	for i, entry := range entries {
+		if !policy.InBusinessHours(entry.Time) {
+			return WorkTimeResult{}, fmt.Errorf("%w: %s entry at %s (index %d)", ErrOutOfBusinessHours, entry.Type, entry.Time.Format("15:04"), i)
+		}
+	}
+
+	result, err := ComputeResult(entries, last, policy)
+	if err != nil {
+		return WorkTimeResult{}, err
+	}
+	if result.WorkTime > policy.MaxWorkTime {
+		return WorkTimeResult{}, fmt.Errorf("%w: worked %s", ErrMaxTimeReached, formatDurationMinutes(result.WorkTime))
+	}
+	if err := CheckContinuousWork(entries, last, policy); err != nil {
+		return WorkTimeResult{}, err
+	}
+	if ok, missing := CheckBreakCompliance(result, policy); !ok {
+		return WorkTimeResult{}, fmt.Errorf("%w: %s missing", ErrBreakNotCompliant, formatDurationMinutes(missing))
+	}
+	return result, nil
 }
 
 // GetLeaveTime returns the minimal time of day that results in a target accounted work time.
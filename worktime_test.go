@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -97,3 +98,467 @@ func dur(hours, minutes int) time.Duration {
 func tim(hours, minutes int) time.Time {
 	return time.Date(2019, time.November, 1, hours, minutes, 0, 0, time.UTC)
 }
+
+func TestEstimateAccountedFromBreakCount(t *testing.T) {
+	assert.Equal(t, AccountedResult{WorkTime: dur(8, 20), BreakTime: dur(0, 40)}, EstimateAccountedFromBreakCount(dur(9, 0), 2, dur(0, 20), DefaultPolicy))
+	assert.Equal(t, AccountedResult{WorkTime: dur(9, 0), BreakTime: dur(0, 40)}, EstimateAccountedFromBreakCount(dur(9, 40), 2, dur(0, 20), DefaultPolicy))
+	assert.Equal(t, AccountedResult{WorkTime: dur(9, 15), BreakTime: dur(0, 45)}, EstimateAccountedFromBreakCount(dur(10, 0), 2, dur(0, 20), DefaultPolicy))
+	assert.Equal(t, AccountedResult{WorkTime: dur(6, 0), BreakTime: dur(0, 30)}, EstimateAccountedFromBreakCount(dur(6, 30), 0, dur(0, 20), DefaultPolicy))
+}
+
+func TestAccountedResultToEntries(t *testing.T) {
+	testCases := []AccountedResult{
+		{WorkTime: dur(5, 0), BreakTime: dur(0, 0)},
+		{WorkTime: dur(6, 0), BreakTime: dur(0, 15)},
+		{WorkTime: dur(8, 0), BreakTime: dur(0, 30)},
+		{WorkTime: dur(9, 13), BreakTime: dur(0, 45)},
+		{WorkTime: dur(10, 0), BreakTime: dur(1, 5)},
+	}
+
+	for _, c := range testCases {
+		t.Run(fmt.Sprintf("Test %s, %s", c.WorkTime, c.BreakTime), func(t *testing.T) {
+			entries := c.ToEntries(tim(0, 0), tim(8, 0))
+			assert.Equal(t, tim(8, 0), entries[0].Time)
+			assert.Equal(t, tim(8, 0).Add(c.WorkTime+c.BreakTime), entries[len(entries)-1].Time)
+
+			result, err := ComputeAccountedFromEntries(entries)
+			assert.NoError(t, err)
+			assert.Equal(t, c, result)
+		})
+	}
+}
+
+func TestComputeResult(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 15)},
+	}
+
+	result, err := ComputeResult(entries, tim(17, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, WorkTimeResult{
+		Start:              tim(8, 0),
+		End:                tim(17, 0),
+		WorkTime:           dur(8, 45),
+		BreakTime:          dur(0, 15),
+		LongestBreak:       dur(0, 15),
+		AccountedWorkTime:  dur(8, 30),
+		AccountedBreakTime: dur(0, 30),
+	}, result)
+	assert.Equal(t, dur(9, 0), result.Presence())
+}
+
+func FuzzComputeWorkTime(f *testing.F) {
+	f.Add([]byte{0, 120, 1, 240})
+	f.Add([]byte{0, 60, 1, 15, 0, 200, 1, 30})
+	f.Add([]byte{0, 90, 2, 45, 0, 180, 1, 10})
+	f.Add([]byte{0, 255, 0, 255, 0, 255})
+	f.Add([]byte{1, 10, 0, 20})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// each pair of bytes describes an entry type and the minutes since the previous entry
+		entries := make([]Entry, 0, len(data)/2)
+		entryTypes := []EntryType{EntryTypeCome, EntryTypeLeave, EntryTypeTrip}
+		entryTime := tim(6, 0)
+		for i := 0; i+1 < len(data); i += 2 {
+			entryTime = entryTime.Add(time.Duration(data[i+1]) * time.Minute)
+			entries = append(entries, Entry{Type: entryTypes[int(data[i])%len(entryTypes)], Time: entryTime})
+		}
+		now := entryTime.Add(time.Minute)
+
+		workTime, startTime, breakTime, err := computeWorkTime(entries, now)
+		if err != nil {
+			return
+		}
+
+		presence := now.Sub(startTime)
+		if entries[len(entries)-1].Type == EntryTypeLeave {
+			presence = entries[len(entries)-1].Time.Sub(startTime)
+		}
+		assert.LessOrEqual(t, workTime, presence)
+		assert.GreaterOrEqual(t, workTime, time.Duration(0))
+		assert.GreaterOrEqual(t, breakTime, time.Duration(0))
+
+		accountedWorkTime, accountedBreakTime := DefaultPolicy.AccountedWorkTime(workTime, breakTime)
+		assert.LessOrEqual(t, accountedWorkTime, DefaultPolicy.MaxWorkTime)
+		assert.GreaterOrEqual(t, accountedBreakTime, time.Duration(0))
+		assert.Equal(t, workTime+breakTime, accountedWorkTime+accountedBreakTime)
+	})
+}
+
+func TestComputeResultIgnoreBreaksBelow(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(10, 1).Add(30 * time.Second)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 28)+30*time.Second, result.WorkTime)
+
+	policy := DefaultPolicy
+	policy.IgnoreBreaksBelow = 2 * time.Minute
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 30), result.WorkTime)
+	assert.Equal(t, dur(0, 30), result.BreakTime)
+}
+
+func TestComputeWorkTimeTripOutsideWork(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeTrip, Time: tim(12, 30)},
+		{Type: EntryTypeCome, Time: tim(14, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	_, _, _, err := ComputeWorkTime(entries)
+	assert.ErrorIs(t, err, ErrTripOutsideWork)
+	assert.Contains(t, err.Error(), "index 2")
+
+	_, _, _, err = ComputeWorkTime(entries[2:])
+	assert.ErrorIs(t, err, ErrTripOutsideWork)
+}
+
+func TestComputeWorkTimeTripOnly(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(11, 30)},
+		{Type: EntryTypeLeave, Time: tim(16, 0)},
+	}
+
+	// trips are fully counted as work time, so there is no break
+	workTime, start, breakTime, err := computeWorkTime(entries, tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), workTime)
+	assert.Equal(t, tim(8, 0), start)
+	assert.Zero(t, breakTime)
+
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 30), result.TripTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, result.Presence(), result.WorkTime)
+}
+
+func TestComputeResultTripsAsBreak(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(13, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(9, 0), result.WorkTime)
+	assert.Equal(t, dur(1, 0), result.TripTime)
+	assert.Equal(t, dur(8, 30), result.AccountedWorkTime)
+
+	policy := DefaultPolicy
+	policy.TripsAsBreak = true
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.WorkTime)
+	assert.Equal(t, dur(1, 0), result.BreakTime)
+	assert.Equal(t, dur(8, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(1, 0), result.AccountedBreakTime)
+}
+
+func TestComputeResultOnCall(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeOnCall, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(16, 0)},
+		{Type: EntryTypeLeave, Time: tim(18, 0)},
+	}
+
+	result, err := ComputeResult(entries, tim(19, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 0), result.OnCallTime)
+	assert.Equal(t, dur(4, 0), result.UncreditedOnCallTime)
+	assert.Equal(t, dur(6, 0), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, dur(6, 0), result.AccountedWorkTime)
+	assert.Zero(t, result.AccountedBreakTime)
+
+	// uncredited on-call time does not count as break for the break rules
+	policy := DefaultPolicy
+	policy.OnCallFactor = 0.25
+	result, err = ComputeResult(entries, tim(19, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 0), result.OnCallTime)
+	assert.Equal(t, dur(3, 0), result.UncreditedOnCallTime)
+	assert.Equal(t, dur(7, 0), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+	assert.Equal(t, dur(6, 30), result.AccountedWorkTime)
+	assert.Equal(t, dur(0, 30), result.AccountedBreakTime)
+	assert.Equal(t, result.Presence(), result.WorkTime+result.BreakTime+result.UncreditedOnCallTime)
+
+	_, err = ComputeResult(append(entries[:2:2], Entry{Type: EntryTypeLeave, Time: tim(18, 0)}), tim(19, 0), policy)
+	assert.Error(t, err)
+}
+
+func TestComputeAccountedFromEntriesOnCall(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(7, 0)},
+		{Type: EntryTypeOnCall, Time: tim(11, 0)},
+		{Type: EntryTypeCome, Time: tim(13, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+
+	// on-call time is no break, so the mandated break is deducted
+	workTime, _, breakTime, err := ComputeWorkTime(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), workTime)
+	assert.Zero(t, breakTime)
+
+	result, err := ComputeAccountedFromEntries(entries)
+	assert.NoError(t, err)
+	assert.Equal(t, AccountedResult{WorkTime: dur(7, 30), BreakTime: dur(0, 30)}, result)
+
+	remaining, err := remainingWorkTime(entries, dur(8, 0), tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(0, 30), remaining)
+
+	before, after, err := SplitAt(entries, tim(12, 0), tim(18, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 0), before.WorkTime)
+	assert.Equal(t, dur(1, 0), before.OnCallTime)
+	assert.Zero(t, before.BreakTime)
+	assert.Equal(t, dur(4, 0), after.WorkTime)
+	assert.Zero(t, after.BreakTime)
+
+	entries[3].Time = tim(20, 0)
+	capTime, ok, err := CapReachedAt(entries, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, tim(19, 45), capTime)
+}
+
+func TestLiveResult(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+	}
+
+	result, err := LiveResult(entries, tim(12, 0), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(4, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(7, 30), result.ProjectedWork)
+
+	// current work well below the target
+	result, err = LiveResult(entries, tim(9, 0), dur(9, 30), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(8, 45), result.ProjectedWork)
+
+	result, err = LiveResult(entries, tim(12, 0), dur(5, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 0), result.ProjectedWork)
+
+	// already beyond the target
+	result, err = LiveResult(entries, tim(15, 0), dur(6, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 30), result.ProjectedWork)
+
+	entries = append(entries, Entry{Type: EntryTypeLeave, Time: tim(12, 0)}, Entry{Type: EntryTypeCome, Time: tim(12, 20)})
+	result, err = LiveResult(entries, tim(13, 20), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(7, 50), result.ProjectedWork)
+
+	entries = append(entries, Entry{Type: EntryTypeLeave, Time: tim(15, 0)})
+	result, err = LiveResult(entries, tim(16, 0), dur(8, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, result.AccountedWorkTime, result.ProjectedWork)
+}
+
+func TestComputeLenientOutOfBusinessHours(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(5, 0)},
+		{Type: EntryTypeLeave, Time: tim(11, 0)},
+	}
+
+	result, warnings, err := ComputeLenient(entries, tim(18, 0), DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 0), result.AccountedWorkTime)
+	assert.Equal(t, []string{"come entry at 05:00 (index 0): business hours are from 6:30 to 21:00"}, warnings)
+}
+
+func TestComputeStrict(t *testing.T) {
+	valid := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	modified := func(i int, entry Entry) []Entry {
+		entries := append([]Entry{}, valid...)
+		entries[i] = entry
+		return entries
+	}
+
+	result, err := ComputeStrict(valid, DefaultPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 30), result.AccountedWorkTime)
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		err     error
+	}{
+		{name: "empty", entries: nil, err: ErrNoEntries},
+		{name: "unsorted", entries: modified(2, Entry{Type: EntryTypeCome, Time: tim(11, 0)}), err: ErrUnsortedEntries},
+		{name: "mixed days", entries: modified(3, Entry{Type: EntryTypeLeave, Time: tim(17, 0).AddDate(0, 0, 1)}), err: ErrMixedDays},
+		{name: "open", entries: valid[:3], err: ErrOpenDay},
+		{name: "sequence", entries: modified(2, Entry{Type: EntryTypeLeave, Time: tim(12, 30)}), err: ErrInvalidSequence},
+		{name: "trip", entries: modified(2, Entry{Type: EntryTypeTrip, Time: tim(12, 30)}), err: ErrTripOutsideWork},
+		{name: "business hours", entries: modified(0, Entry{Type: EntryTypeCome, Time: tim(6, 0)}), err: ErrOutOfBusinessHours},
+		{name: "max work time", entries: modified(3, Entry{Type: EntryTypeLeave, Time: tim(20, 45)}), err: ErrMaxTimeReached},
+		{name: "break", entries: modified(2, Entry{Type: EntryTypeCome, Time: tim(12, 10)}), err: ErrBreakNotCompliant},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ComputeStrict(test.entries, DefaultPolicy)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestComputeResultMinShiftLength(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(9, 0)},
+		{Type: EntryTypeCome, Time: tim(9, 15)},
+		{Type: EntryTypeLeave, Time: tim(9, 45)},
+	}
+
+	policy := DefaultPolicy
+	policy.MinShiftLength = dur(3, 0)
+	result, err := ComputeResult(entries, tim(12, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(1, 30), result.WorkTime)
+	assert.Equal(t, dur(3, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(0, 15), result.AccountedBreakTime)
+
+	entries[3].Time = tim(13, 15)
+	result, err = ComputeResult(entries, tim(14, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(5, 0), result.AccountedWorkTime)
+
+	_, err = ComputeResult(nil, tim(12, 0), policy)
+	assert.ErrorIs(t, err, ErrNoEntries)
+}
+
+func TestComputeResultMaxCreditedBreak(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(13, 30)},
+		{Type: EntryTypeLeave, Time: tim(17, 30)},
+	}
+
+	policy := DefaultPolicy
+	policy.MaxCreditedBreak = time.Hour
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.AccountedWorkTime)
+	assert.Equal(t, dur(1, 0), result.AccountedBreakTime)
+	assert.Equal(t, dur(0, 30), result.ExcessBreakTime)
+}
+
+func TestComputeResultFixedBreakWindows(t *testing.T) {
+	policy := DefaultPolicy
+	policy.FixedBreakWindows = []DailyWindow{{Start: dur(12, 0), End: dur(12, 30)}}
+
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(11, 50)},
+		{Type: EntryTypeCome, Time: tim(12, 40)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 10), result.WorkTime)
+	assert.Equal(t, dur(0, 50), result.BreakTime)
+
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(16, 0)},
+	}
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 30), result.WorkTime)
+	assert.Equal(t, dur(0, 30), result.BreakTime)
+	assert.Equal(t, dur(7, 30), result.AccountedWorkTime)
+
+	// a trip counted as break is not deducted again by the window
+	entries = []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(11, 45)},
+		{Type: EntryTypeCome, Time: tim(12, 45)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}
+	policy.TripsAsBreak = true
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(8, 0), result.WorkTime)
+	assert.Equal(t, dur(1, 0), result.BreakTime)
+
+	policy.TripsAsBreak = false
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(9, 0), result.WorkTime)
+	assert.Zero(t, result.BreakTime)
+}
+
+func TestHasEdits(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(12, 0), Edited: true, EditedBy: "hr"},
+		{Type: EntryTypeCome, Time: tim(12, 30), Source: "web"},
+	}
+	assert.True(t, HasEdits(entries))
+	assert.False(t, HasEdits(entries[:1]))
+
+	policy := DefaultPolicy
+	policy.SourceRounding = map[string]RoundConfig{"": {Granularity: 5 * time.Minute}}
+	assert.True(t, HasEdits(removeShortBreaks(roundEntries(entries, policy), time.Minute)))
+
+	data, err := json.Marshal(entries)
+	assert.NoError(t, err)
+	var decoded []Entry
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "hr", decoded[1].EditedBy)
+	assert.True(t, HasEdits(decoded))
+}
+
+func TestComputeResultEarliestCountableBreak(t *testing.T) {
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeLeave, Time: tim(8, 20)},
+		{Type: EntryTypeCome, Time: tim(8, 50)},
+		{Type: EntryTypeLeave, Time: tim(15, 0)},
+	}
+
+	policy := DefaultPolicy
+	policy.EarliestCountableBreak = 30 * time.Minute
+	result, err := ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(6, 40), result.WorkTime)
+	assert.Equal(t, dur(0, 20), result.BreakTime)
+	assert.Equal(t, dur(0, 20), result.LongestBreak)
+	assert.Equal(t, dur(6, 30), result.AccountedWorkTime)
+
+	entries[2].Time = tim(8, 25)
+	result, err = ComputeResult(entries, tim(18, 0), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, dur(7, 0), result.WorkTime)
+	assert.Equal(t, dur(0, 0), result.BreakTime)
+}